@@ -0,0 +1,81 @@
+// Package app assembles the operator's long-running subsystems - LLM
+// backend preflight, ambient-vertex secret watching, and propagated-copy
+// GC - behind a single Run call so the operator's entry point only needs
+// to construct a Config and call it once.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/controllers/gc"
+	"github.com/ambient-code/vteam/components/operator/internal/preflight"
+)
+
+// defaultHealthAddr is the address the /healthz and /readyz HTTP server
+// listens on absent an explicit Config.HealthAddr.
+const defaultHealthAddr = ":8080"
+
+// Config holds what Run needs to start every long-running subsystem.
+type Config struct {
+	// OperatorNamespace is the namespace the operator's own Deployment and
+	// its backend-credential secrets (ambient-vertex, ambient-anthropic,
+	// ambient-azure-openai) live in.
+	OperatorNamespace string
+	// DeploymentName is the operator's own Deployment, used as the
+	// InvolvedObject for the Kubernetes Events the ambient-vertex watcher
+	// emits.
+	DeploymentName string
+	// HealthAddr overrides defaultHealthAddr; empty keeps the default.
+	HealthAddr string
+	// OwnerExists is passed through to gc.NewController to check whether a
+	// copied Secret/ConfigMap's owning AgenticSession still exists. It goes
+	// through a dynamic/CRD client the gc package otherwise has no reason
+	// to depend on, so the caller supplies it.
+	OwnerExists gc.OwnerExistsFunc
+}
+
+// Run validates every enabled LLM backend, then starts the ambient-vertex
+// secret watcher, the orphaned-copy GC controller, and the /healthz and
+// /readyz HTTP handlers, blocking until ctx is cancelled. It fails fast on
+// the initial preflight validation rather than starting to hand out
+// agentic sessions it can't actually run.
+func Run(ctx context.Context, cfg Config) error {
+	if err := preflight.RunAll(ctx,
+		preflight.NewVertexValidator(cfg.OperatorNamespace),
+		preflight.NewAnthropicValidator(cfg.OperatorNamespace),
+		preflight.NewBedrockValidator(cfg.OperatorNamespace),
+		preflight.NewAzureOpenAIValidator(cfg.OperatorNamespace),
+	); err != nil {
+		return fmt.Errorf("preflight validation failed: %w", err)
+	}
+
+	watcher := preflight.NewWatcher(config.K8sClient, cfg.OperatorNamespace, cfg.DeploymentName)
+	if err := watcher.Start(ctx); err != nil {
+		return fmt.Errorf("starting ambient-vertex secret watcher: %w", err)
+	}
+
+	gcController := gc.NewController(config.K8sClient, cfg.OwnerExists)
+	go gcController.Run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", preflight.HealthzHandler())
+	mux.Handle("/readyz", watcher.ReadyzHandler())
+
+	healthAddr := cfg.HealthAddr
+	if healthAddr == "" {
+		healthAddr = defaultHealthAddr
+	}
+	server := &http.Server{Addr: healthAddr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("app: health server stopped: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	return server.Close()
+}
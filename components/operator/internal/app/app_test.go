@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// neverOrphaned is an OwnerExistsFunc that treats every owner as still
+// live, so the test's GC controller never tries to delete anything.
+func neverOrphaned(ctx context.Context, ref metav1.OwnerReference, namespace string) (bool, error) {
+	return true, nil
+}
+
+// TestRun confirms Run actually invokes preflight validation for the
+// enabled backend and, once that passes, starts the ambient-vertex watcher,
+// the GC controller, and the health server - rather than only constructing
+// them for validator_test.go-style unit tests to call in isolation.
+func TestRun(t *testing.T) {
+	os.Unsetenv("CLAUDE_CODE_USE_VERTEX")
+	os.Unsetenv("CLAUDE_CODE_USE_BEDROCK")
+	os.Unsetenv("CLAUDE_CODE_USE_AZURE")
+
+	origK8sClient := config.K8sClient
+	defer func() { config.K8sClient = origK8sClient }()
+
+	tests := []struct {
+		name           string
+		existingSecret *corev1.Secret
+		wantErr        bool
+		errContains    string
+	}{
+		{
+			name: "valid ambient-anthropic secret starts the watcher and health server",
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: types.AmbientAnthropicSecretName, Namespace: "test-ns"},
+				Data:       map[string][]byte{"api_key": []byte("sk-ant-abc123")},
+			},
+		},
+		{
+			name:        "missing ambient-anthropic secret fails preflight before anything starts",
+			wantErr:     true,
+			errContains: "anthropic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.existingSecret != nil {
+				config.K8sClient = fake.NewSimpleClientset(tt.existingSecret)
+			} else {
+				config.K8sClient = fake.NewSimpleClientset()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- Run(ctx, Config{OperatorNamespace: "test-ns", HealthAddr: "127.0.0.1:0", OwnerExists: neverOrphaned})
+			}()
+
+			select {
+			case err := <-errCh:
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("Run() expected error but got nil")
+					}
+					if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+						t.Errorf("Run() error = %q, want substring %q", err.Error(), tt.errContains)
+					}
+					return
+				}
+				t.Fatalf("Run() returned before ctx was cancelled, err = %v", err)
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantErr {
+					t.Fatal("Run() expected to fail preflight immediately but is still running")
+				}
+				cancel()
+				if err := <-errCh; err != nil {
+					t.Errorf("Run() unexpected error after cancel = %v", err)
+				}
+			}
+		})
+	}
+}
+
+// contains reports whether s contains substr.
+func contains(s, substr string) bool {
+	if len(substr) == 0 {
+		return true
+	}
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
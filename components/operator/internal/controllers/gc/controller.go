@@ -0,0 +1,329 @@
+// Package gc periodically reclaims Secrets and ConfigMaps the operator
+// copied into session namespaces (internal/handlers.propagateResource)
+// after their source, owner, or content no longer justifies keeping them
+// around - borrowing the periodic-GC-over-a-workqueue pattern Pinniped's
+// supervisor storage controllers use for expired session storage.
+package gc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// defaultInterval is how often the controller re-scans every namespace for
+// copied resources, absent an explicit Interval.
+const defaultInterval = 10 * time.Minute
+
+// defaultJitterFactor spreads the resync across up to 20% more than
+// Interval, so a large cluster running many operator replicas doesn't scan
+// every namespace in lockstep.
+const defaultJitterFactor = 0.2
+
+// reclaimedEventReason is the Kubernetes Event reason recorded against a
+// resource the controller deletes, so administrators can audit reclaimed
+// copies with `kubectl get events --field-selector reason=StaleCopyReclaimed`.
+const reclaimedEventReason = "StaleCopyReclaimed"
+
+// OwnerExistsFunc reports whether the object an OwnerReference points at is
+// still live. It's injected rather than baked in because checking an
+// AgenticSession's existence goes through a dynamic/CRD client the gc
+// package otherwise has no reason to depend on.
+type OwnerExistsFunc func(ctx context.Context, ref metav1.OwnerReference, namespace string) (bool, error)
+
+// Controller periodically lists every Secret and ConfigMap carrying
+// types.CopiedFromAnnotation across all namespaces and deletes the ones that
+// are orphaned: their source is gone, every owning AgenticSession is gone,
+// or (for copies with no owner reference at all) their data has drifted
+// from the source they were copied from.
+type Controller struct {
+	KubeClient  kubernetes.Interface
+	OwnerExists OwnerExistsFunc
+	// Interval overrides defaultInterval; zero keeps the default.
+	Interval time.Duration
+
+	recorder record.EventRecorder
+	queue    workqueue.RateLimitingInterface
+}
+
+// NewController returns a Controller ready to Run. ownerExists is required;
+// it is how the controller learns whether an AgenticSession owner reference
+// is still live.
+func NewController(kubeClient kubernetes.Interface, ownerExists OwnerExistsFunc) *Controller {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(metav1.NamespaceAll)})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "vteam-operator-gc"})
+
+	return &Controller{
+		KubeClient:  kubeClient,
+		OwnerExists: ownerExists,
+		recorder:    recorder,
+		queue:       workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+}
+
+// Run scans for orphaned copies on a jittered Interval and drains the
+// resulting work queue until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	defer c.queue.ShutDown()
+
+	go wait.JitterUntil(func() { c.scan(ctx) }, c.interval(), defaultJitterFactor, true, ctx.Done())
+	go wait.Until(func() { c.runWorker(ctx) }, time.Second, ctx.Done())
+
+	<-ctx.Done()
+}
+
+func (c *Controller) interval() time.Duration {
+	if c.Interval == 0 {
+		return defaultInterval
+	}
+	return c.Interval
+}
+
+// scan enqueues a key for every Secret and ConfigMap, in any namespace,
+// carrying types.CopiedFromAnnotation. Resources without the annotation are
+// never enqueued - the same defensive check cleanupPropagated already makes
+// before deleting a single resource.
+func (c *Controller) scan(ctx context.Context) {
+	secrets, err := c.KubeClient.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("gc: failed to list secrets: %v", err)
+	} else {
+		for i := range secrets.Items {
+			if secrets.Items[i].Annotations[types.CopiedFromAnnotation] != "" {
+				c.queue.Add(objectKey("Secret", secrets.Items[i].Namespace, secrets.Items[i].Name))
+			}
+		}
+	}
+
+	configMaps, err := c.KubeClient.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("gc: failed to list configmaps: %v", err)
+	} else {
+		for i := range configMaps.Items {
+			if configMaps.Items[i].Annotations[types.CopiedFromAnnotation] != "" {
+				c.queue.Add(objectKey("ConfigMap", configMaps.Items[i].Namespace, configMaps.Items[i].Name))
+			}
+		}
+	}
+}
+
+func (c *Controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.ReconcileKey(ctx, key.(string)); err != nil {
+		log.Printf("gc: failed to reconcile %q: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// ReconcileKey decides whether the resource identified by key is an
+// orphaned copy and, if so, deletes it and emits a StaleCopyReclaimed Event.
+// It is exported so tests can drive a single reconcile without running the
+// full scan/workqueue loop.
+func (c *Controller) ReconcileKey(ctx context.Context, key string) error {
+	kind, namespace, name, err := splitObjectKey(key)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "Secret":
+		return c.reconcileSecret(ctx, namespace, name)
+	case "ConfigMap":
+		return c.reconcileConfigMap(ctx, namespace, name)
+	default:
+		return fmt.Errorf("gc: unknown kind %q in key %q", kind, key)
+	}
+}
+
+func (c *Controller) reconcileSecret(ctx context.Context, namespace, name string) error {
+	secret, err := c.KubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	copiedFrom := secret.Annotations[types.CopiedFromAnnotation]
+	if copiedFrom == "" {
+		return nil
+	}
+
+	sourceNamespace, sourceName, err := splitCopiedFrom(copiedFrom)
+	if err != nil {
+		return err
+	}
+	source, err := c.KubeClient.CoreV1().Secrets(sourceNamespace).Get(ctx, sourceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return c.reclaimSecret(ctx, secret, "source secret no longer exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	orphaned, reason, err := c.isOrphaned(ctx, secret.Namespace, secret.OwnerReferences, hashBytesData(secret.Data), hashBytesData(source.Data))
+	if err != nil {
+		return err
+	}
+	if orphaned {
+		return c.reclaimSecret(ctx, secret, reason)
+	}
+	return nil
+}
+
+func (c *Controller) reconcileConfigMap(ctx context.Context, namespace, name string) error {
+	cm, err := c.KubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	copiedFrom := cm.Annotations[types.CopiedFromAnnotation]
+	if copiedFrom == "" {
+		return nil
+	}
+
+	sourceNamespace, sourceName, err := splitCopiedFrom(copiedFrom)
+	if err != nil {
+		return err
+	}
+	source, err := c.KubeClient.CoreV1().ConfigMaps(sourceNamespace).Get(ctx, sourceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return c.reclaimConfigMap(ctx, cm, "source configmap no longer exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	orphaned, reason, err := c.isOrphaned(ctx, cm.Namespace, cm.OwnerReferences, hashStringData(cm.Data), hashStringData(source.Data))
+	if err != nil {
+		return err
+	}
+	if orphaned {
+		return c.reclaimConfigMap(ctx, cm, reason)
+	}
+	return nil
+}
+
+// isOrphaned implements conditions (b) and (c): a copy with owner
+// references is orphaned once none of them are still live; a copy with no
+// owner references at all is only orphaned once its data has actually
+// drifted from the source, since a fresh, still-matching, ownerless copy
+// may simply not have had an owner reference attached yet.
+func (c *Controller) isOrphaned(ctx context.Context, namespace string, refs []metav1.OwnerReference, currentHash, sourceHash string) (bool, string, error) {
+	if len(refs) == 0 {
+		if currentHash != sourceHash {
+			return true, "no owner reference claims it and its data no longer matches the source", nil
+		}
+		return false, "", nil
+	}
+
+	for _, ref := range refs {
+		alive, err := c.OwnerExists(ctx, ref, namespace)
+		if err != nil {
+			return false, "", err
+		}
+		if alive {
+			return false, "", nil
+		}
+	}
+	return true, "all owner references point to AgenticSessions that no longer exist", nil
+}
+
+func (c *Controller) reclaimSecret(ctx context.Context, secret *corev1.Secret, reason string) error {
+	if err := c.KubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	c.recorder.Eventf(secret, corev1.EventTypeNormal, reclaimedEventReason, "reclaimed stale copy of secret: %s", reason)
+	log.Printf("gc: reclaimed secret %s/%s: %s", secret.Namespace, secret.Name, reason)
+	return nil
+}
+
+func (c *Controller) reclaimConfigMap(ctx context.Context, cm *corev1.ConfigMap, reason string) error {
+	if err := c.KubeClient.CoreV1().ConfigMaps(cm.Namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	c.recorder.Eventf(cm, corev1.EventTypeNormal, reclaimedEventReason, "reclaimed stale copy of configmap: %s", reason)
+	log.Printf("gc: reclaimed configmap %s/%s: %s", cm.Namespace, cm.Name, reason)
+	return nil
+}
+
+func objectKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func splitObjectKey(key string) (kind, namespace, name string, err error) {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("gc: malformed key %q", key)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+func splitCopiedFrom(copiedFrom string) (namespace, name string, err error) {
+	parts := strings.SplitN(copiedFrom, "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("gc: malformed %s annotation %q", types.CopiedFromAnnotation, copiedFrom)
+	}
+	return parts[0], parts[1], nil
+}
+
+func hashBytesData(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write(data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashStringData(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte(data[k]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,196 @@
+package gc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// alwaysAlive and alwaysGone are canned OwnerExistsFunc implementations for
+// tests that don't need per-owner behavior.
+func alwaysAlive(ctx context.Context, ref metav1.OwnerReference, namespace string) (bool, error) {
+	return true, nil
+}
+func alwaysGone(ctx context.Context, ref metav1.OwnerReference, namespace string) (bool, error) {
+	return false, nil
+}
+
+func ownerRef(name, uid string) metav1.OwnerReference {
+	return metav1.OwnerReference{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: name, UID: metav1.UID(uid)}
+}
+
+func TestReconcileKey_Secret(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		secret       *corev1.Secret
+		sourceSecret *corev1.Secret
+		ownerExists  OwnerExistsFunc
+		wantDeleted  bool
+	}{
+		{
+			name: "skips a secret without the annotation",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "session-ns"},
+			},
+			ownerExists: alwaysGone,
+			wantDeleted: false,
+		},
+		{
+			name: "reclaims a copy whose source no longer exists",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "session-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					OwnerReferences: []metav1.OwnerReference{ownerRef("test-session", "uid-1")},
+				},
+			},
+			ownerExists: alwaysAlive,
+			wantDeleted: true,
+		},
+		{
+			name: "reclaims a copy whose owning sessions are all gone",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "session-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					OwnerReferences: []metav1.OwnerReference{ownerRef("test-session", "uid-1")},
+				},
+				Data: map[string][]byte{"key.json": []byte("data")},
+			},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte("data")},
+			},
+			ownerExists: alwaysGone,
+			wantDeleted: true,
+		},
+		{
+			name: "keeps a copy with at least one live owner",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "session-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					OwnerReferences: []metav1.OwnerReference{ownerRef("test-session", "uid-1")},
+				},
+				Data: map[string][]byte{"key.json": []byte("data")},
+			},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte("data")},
+			},
+			ownerExists: alwaysAlive,
+			wantDeleted: false,
+		},
+		{
+			name: "keeps an ownerless copy whose data still matches the source",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ca-bundle", Namespace: "session-ns",
+					Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ca-bundle"},
+				},
+				Data: map[string][]byte{"ca.crt": []byte("cert")},
+			},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"ca.crt": []byte("cert")},
+			},
+			ownerExists: alwaysAlive,
+			wantDeleted: false,
+		},
+		{
+			name: "reclaims an ownerless copy whose data has drifted from the source",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ca-bundle", Namespace: "session-ns",
+					Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ca-bundle"},
+				},
+				Data: map[string][]byte{"ca.crt": []byte("stale-cert")},
+			},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"ca.crt": []byte("current-cert")},
+			},
+			ownerExists: alwaysAlive,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			if _, err := fakeClient.CoreV1().Secrets(tt.secret.Namespace).Create(ctx, tt.secret, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("failed to seed secret: %v", err)
+			}
+			if tt.sourceSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.sourceSecret.Namespace).Create(ctx, tt.sourceSecret, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to seed source secret: %v", err)
+				}
+			}
+
+			c := NewController(fakeClient, tt.ownerExists)
+			key := objectKey("Secret", tt.secret.Namespace, tt.secret.Name)
+			if err := c.ReconcileKey(ctx, key); err != nil {
+				t.Fatalf("ReconcileKey() unexpected error = %v", err)
+			}
+
+			_, err := fakeClient.CoreV1().Secrets(tt.secret.Namespace).Get(ctx, tt.secret.Name, metav1.GetOptions{})
+			deleted := apierrors.IsNotFound(err)
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v (get err = %v)", deleted, tt.wantDeleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileKey_ConfigMapSourceGone(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ca-bundle", Namespace: "session-ns",
+			Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ca-bundle"},
+		},
+	}
+	if _, err := fakeClient.CoreV1().ConfigMaps("session-ns").Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to seed configmap: %v", err)
+	}
+
+	c := NewController(fakeClient, alwaysAlive)
+	if err := c.ReconcileKey(ctx, objectKey("ConfigMap", "session-ns", "ca-bundle")); err != nil {
+		t.Fatalf("ReconcileKey() unexpected error = %v", err)
+	}
+
+	_, err := fakeClient.CoreV1().ConfigMaps("session-ns").Get(ctx, "ca-bundle", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Error("expected configmap to be reclaimed once its source is gone")
+	}
+}
+
+func TestScan_OnlyEnqueuesAnnotatedResources(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: "copied", Namespace: "session-ns",
+			Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/copied"},
+		}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "manual", Namespace: "session-ns"}},
+	)
+
+	c := NewController(fakeClient, alwaysAlive)
+	c.scan(ctx)
+
+	if c.queue.Len() != 1 {
+		t.Fatalf("queue length = %d, want 1 (only the annotated secret)", c.queue.Len())
+	}
+	key, _ := c.queue.Get()
+	if key.(string) != objectKey("Secret", "session-ns", "copied") {
+		t.Errorf("queued key = %v, want the annotated secret's key", key)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnsureOwnerRef adds ref to obj's OwnerReferences if it isn't already
+// present, modeled on OpenShift's resourcemerge.EnsureOwnerRef. It matches
+// existing references by APIVersion+Kind+Name; a match whose UID differs is
+// evicted rather than kept, because that's a stale reference left behind
+// when an AgenticSession is deleted and recreated under the same name -
+// keeping it would leave a dangling owner ref that blocks garbage
+// collection on the new object. It returns whether it mutated
+// obj's OwnerReferences.
+//
+// ref.Controller is honored: at most one controller reference is allowed,
+// so if ref sets Controller=true and a different UID is already the
+// controller, EnsureOwnerRef returns an error instead of silently adding a
+// second controller.
+//
+// Not yet wired: this was meant to replace the owner-ref handling inline in
+// copySecretToNamespace, the function the live AgenticSession reconciler
+// actually calls, but that function lives outside this component's tracked
+// tree (see PropagateAll's doc comment in propagate.go for the same gap on
+// the propagation side), so the rewiring can't land here. Within this
+// package, propagateSecret/propagateConfigMap don't call this either: they
+// merge owner references via mergeOwnerRef (propagate.go), which allows more
+// than one Controller=true reference on a propagated resource, since every
+// session propagating the same resource sets Controller=true on its own
+// reference. EnsureOwnerRef's single-controller guard is right for a
+// resource owned by exactly one object, so it's kept for that case and
+// exercised by this package's own tests, but it has no caller in the
+// propagation subsystem today.
+func EnsureOwnerRef(obj metav1.Object, ref metav1.OwnerReference) (bool, error) {
+	existingRefs := obj.GetOwnerReferences()
+
+	if ref.Controller != nil && *ref.Controller {
+		for _, existing := range existingRefs {
+			if existing.UID == ref.UID {
+				continue
+			}
+			if existing.APIVersion == ref.APIVersion && existing.Kind == ref.Kind && existing.Name == ref.Name {
+				// Same identity, different UID: a stale reference left by a
+				// deleted-and-recreated owner, not a genuine conflict. The
+				// eviction loop below drops it and adds ref in its place.
+				continue
+			}
+			if existing.Controller != nil && *existing.Controller {
+				return false, fmt.Errorf("cannot set %s/%s (uid %s) as controller: %s/%s (uid %s) is already the controller",
+					ref.Kind, ref.Name, ref.UID, existing.Kind, existing.Name, existing.UID)
+			}
+		}
+	}
+
+	updated := make([]metav1.OwnerReference, 0, len(existingRefs)+1)
+	changed := false
+	found := false
+	for _, existing := range existingRefs {
+		if existing.APIVersion == ref.APIVersion && existing.Kind == ref.Kind && existing.Name == ref.Name {
+			if existing.UID != ref.UID {
+				changed = true
+				continue
+			}
+			found = true
+		}
+		updated = append(updated, existing)
+	}
+	if !found {
+		updated = append(updated, ref)
+		changed = true
+	}
+
+	if changed {
+		obj.SetOwnerReferences(updated)
+	}
+	return changed, nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsureOwnerRef(t *testing.T) {
+	controllerRef := func(name, uid string) metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion: "vteam.ambient-code/v1alpha1",
+			Kind:       "AgenticSession",
+			Name:       name,
+			UID:        metav1.UID(uid),
+			Controller: boolPtr(true),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		existingRefs []metav1.OwnerReference
+		ref          metav1.OwnerReference
+		wantChanged  bool
+		wantErr      bool
+		wantRefs     []metav1.OwnerReference
+	}{
+		{
+			name:        "appends to an empty list",
+			ref:         controllerRef("test-session", "uid-123"),
+			wantChanged: true,
+			wantRefs:    []metav1.OwnerReference{controllerRef("test-session", "uid-123")},
+		},
+		{
+			name:         "no-op when an identical reference already exists",
+			existingRefs: []metav1.OwnerReference{controllerRef("test-session", "uid-789")},
+			ref:          controllerRef("test-session", "uid-789"),
+			wantChanged:  false,
+			wantRefs:     []metav1.OwnerReference{controllerRef("test-session", "uid-789")},
+		},
+		{
+			name:         "adds a new reference alongside an unrelated owner",
+			existingRefs: []metav1.OwnerReference{{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "other-session", UID: "uid-other"}},
+			ref:          controllerRef("new-session", "uid-new"),
+			wantChanged:  true,
+			wantRefs: []metav1.OwnerReference{
+				{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "other-session", UID: "uid-other"},
+				controllerRef("new-session", "uid-new"),
+			},
+		},
+		{
+			name:         "evicts a stale reference left by a deleted-and-recreated owner",
+			existingRefs: []metav1.OwnerReference{controllerRef("test-session", "uid-old")},
+			ref:          controllerRef("test-session", "uid-new"),
+			wantChanged:  true,
+			wantRefs:     []metav1.OwnerReference{controllerRef("test-session", "uid-new")},
+		},
+		{
+			name: "errors rather than adding a second controller",
+			existingRefs: []metav1.OwnerReference{
+				controllerRef("other-session", "uid-other"),
+			},
+			ref:     controllerRef("new-session", "uid-new"),
+			wantErr: true,
+		},
+		{
+			name: "non-controlling ref does not conflict with an existing controller",
+			existingRefs: []metav1.OwnerReference{
+				controllerRef("owning-session", "uid-owner"),
+			},
+			ref: metav1.OwnerReference{
+				APIVersion: "vteam.ambient-code/v1alpha1",
+				Kind:       "AgenticSession",
+				Name:       "viewer-session",
+				UID:        "uid-viewer",
+			},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{OwnerReferences: tt.existingRefs}
+
+			changed, err := EnsureOwnerRef(obj, tt.ref)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("EnsureOwnerRef() expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureOwnerRef() unexpected error = %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("EnsureOwnerRef() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if tt.wantRefs != nil && len(obj.OwnerReferences) != len(tt.wantRefs) {
+				t.Errorf("OwnerReferences = %+v, want %+v", obj.OwnerReferences, tt.wantRefs)
+			}
+		})
+	}
+}
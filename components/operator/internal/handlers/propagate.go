@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// secretFieldManagerPrefix identifies the operator's writes to copied
+// secrets for Server-Side Apply.
+const secretFieldManagerPrefix = "vteam-operator/secret-propagator"
+
+// secretFieldManager scopes the field manager to the owning session's UID,
+// so that two sessions propagating the same source secret concurrently each
+// own their slice of Type/Data/the CopiedFromAnnotation without one Apply's
+// Force overwriting the other's. It does not extend to OwnerReferences:
+// metadata.ownerReferences is an atomic list under Server-Side Apply, so two
+// Force Applies each declaring a single-entry list would still have the
+// later one replace the earlier one's entry rather than merge with it.
+// reconcileSecretOwnerRef handles that field with an explicit
+// read-merge-write instead.
+func secretFieldManager(owner *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s", secretFieldManagerPrefix, owner.GetUID())
+}
+
+// boolPtr returns a pointer to b, for the *bool fields OwnerReference needs.
+func boolPtr(b bool) *bool { return &b }
+
+// ownerReferenceEqual reports whether a and b are the same owner reference,
+// comparing Controller and BlockOwnerDeletion by pointed-to value rather
+// than pointer identity - a plain != on the structs would always see them
+// as different, since every OwnerReference the operator builds gets its own
+// fresh *bool.
+func ownerReferenceEqual(a, b metav1.OwnerReference) bool {
+	return a.APIVersion == b.APIVersion &&
+		a.Kind == b.Kind &&
+		a.Name == b.Name &&
+		a.UID == b.UID &&
+		boolPtrEqual(a.Controller, b.Controller) &&
+		boolPtrEqual(a.BlockOwnerDeletion, b.BlockOwnerDeletion)
+}
+
+// boolPtrEqual reports whether a and b point to the same bool value,
+// treating nil as distinct from any concrete value.
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PropagateAll runs propagateResource for every policy, so the AgenticSession
+// reconciler can mount whatever the operator is configured to propagate -
+// the ambient-vertex secret and any additional CA bundles or registry
+// credentials - without a dedicated copy function per resource. Every
+// policy is attempted even if an earlier one fails, and the failures are
+// joined into a single error.
+//
+// Not yet wired: the AgenticSession reconciler that would call this instead
+// of copySecretToNamespace lives outside this component's tracked tree, so
+// that reconciler still calls copySecretToNamespace/deleteAmbientVertexSecret
+// directly. PropagateAll/CleanupAll and the gc.Controller that garbage
+// collects their output are exercised only by this package's own tests until
+// the reconciler is repointed at them - inert scaffolding for the
+// declarative migration, not a completed one.
+func PropagateAll(ctx context.Context, policies []types.PropagationPolicy, targetNamespace string, owner *unstructured.Unstructured) error {
+	var errs []error
+	for _, policy := range policies {
+		if err := propagateResource(ctx, policy, targetNamespace, owner); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %w", policy.Kind, policy.SourceNamespace, policy.SourceName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CleanupAll runs cleanupPropagated for every policy.
+func CleanupAll(ctx context.Context, policies []types.PropagationPolicy, targetNamespace string) error {
+	var errs []error
+	for _, policy := range policies {
+		if err := cleanupPropagated(ctx, policy, targetNamespace); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %w", policy.Kind, policy.SourceNamespace, policy.SourceName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// propagateResource mirrors the Secret or ConfigMap named by policy into
+// targetNamespace, owned by owner, creating it if absent and otherwise
+// keeping its data and owner references up to date.
+func propagateResource(ctx context.Context, policy types.PropagationPolicy, targetNamespace string, owner *unstructured.Unstructured) error {
+	if owner == nil {
+		return fmt.Errorf("propagateResource: owner object is nil")
+	}
+
+	switch policy.Kind {
+	case types.PropagatedResourceSecret:
+		return propagateSecret(ctx, policy, targetNamespace, owner)
+	case types.PropagatedResourceConfigMap:
+		return propagateConfigMap(ctx, policy, targetNamespace, owner)
+	default:
+		return fmt.Errorf("propagateResource: unsupported kind %q", policy.Kind)
+	}
+}
+
+// cleanupPropagated deletes the copy of the resource named by policy from
+// targetNamespace, but only if it carries the CopiedFromAnnotation - a
+// same-named resource an administrator created by hand is left alone.
+func cleanupPropagated(ctx context.Context, policy types.PropagationPolicy, targetNamespace string) error {
+	switch policy.Kind {
+	case types.PropagatedResourceSecret:
+		return cleanupPropagatedSecret(ctx, policy, targetNamespace)
+	case types.PropagatedResourceConfigMap:
+		return cleanupPropagatedConfigMap(ctx, policy, targetNamespace)
+	default:
+		return fmt.Errorf("cleanupPropagated: unsupported kind %q", policy.Kind)
+	}
+}
+
+// ownerReferenceFor builds the OwnerReference propagateResource attaches to
+// a copy, controlling or not depending on policy.
+func ownerReferenceFor(owner *unstructured.Unstructured, policy types.PropagationPolicy) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         owner.GetAPIVersion(),
+		Kind:               owner.GetKind(),
+		Name:               owner.GetName(),
+		UID:                owner.GetUID(),
+		Controller:         boolPtr(policy.Controller),
+		BlockOwnerDeletion: boolPtr(policy.Controller),
+	}
+}
+
+// copiedFrom formats the CopiedFromAnnotation value for policy's source.
+func copiedFrom(policy types.PropagationPolicy) string {
+	return policy.SourceNamespace + "/" + policy.SourceName
+}
+
+// isForeignCopy reports whether an existing resource at the target name
+// lacks CopiedFromAnnotation, meaning it wasn't created by this propagation
+// subsystem - most likely an administrator created a same-named resource by
+// hand - and so must be left alone rather than overwritten or deleted, the
+// same rule cleanupPropagated already applies on the delete side.
+func isForeignCopy(annotations map[string]string) bool {
+	return annotations[types.CopiedFromAnnotation] == ""
+}
+
+// filterKeys returns the subset of data whose key is in keys, or data
+// unchanged when keys is empty.
+func filterKeys[V any](data map[string]V, keys []string) map[string]V {
+	if len(keys) == 0 {
+		return data
+	}
+	filtered := make(map[string]V, len(keys))
+	for _, key := range keys {
+		if v, ok := data[key]; ok {
+			filtered[key] = v
+		}
+	}
+	return filtered
+}
+
+// propagateSecret mirrors the source secret into targetNamespace using
+// Server-Side Apply for Type, Data, and the CopiedFromAnnotation, so that
+// concurrent reconciles for different owning sessions each own their slice
+// of those fields instead of one Force Apply clobbering the other's write.
+// An administrator's unrelated annotations or labels on the same object are
+// left alone, and a same-named secret an administrator created by hand -
+// recognized by the absence of CopiedFromAnnotation - is left untouched
+// entirely rather than adopted. OwnerReferences is handled separately by
+// reconcileSecretOwnerRef, since it's an atomic list SSA can't merge two
+// single-entry declarations of it into.
+func propagateSecret(ctx context.Context, policy types.PropagationPolicy, targetNamespace string, owner *unstructured.Unstructured) error {
+	src, err := config.K8sClient.CoreV1().Secrets(policy.SourceNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("source secret '%s/%s' not found: %w", policy.SourceNamespace, policy.SourceName, err)
+	}
+	data := filterKeys(src.Data, policy.Keys)
+	secretType := src.Type
+	if secretType == "" {
+		secretType = corev1.SecretTypeOpaque
+	}
+	if err := validateSecretData(secretType, data); err != nil {
+		return fmt.Errorf("source secret '%s/%s': %w", policy.SourceNamespace, policy.SourceName, err)
+	}
+	ownerRef := ownerReferenceFor(owner, policy)
+
+	existing, err := config.K8sClient.CoreV1().Secrets(targetNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if isForeignCopy(existing.Annotations) {
+			return nil
+		}
+		if err := reconcileExistingSecretType(ctx, policy, targetNamespace, existing, secretType); err != nil {
+			return err
+		}
+	}
+
+	apply := corev1ac.Secret(policy.SourceName, targetNamespace).
+		WithType(secretType).
+		WithData(data).
+		WithAnnotations(map[string]string{types.CopiedFromAnnotation: copiedFrom(policy)})
+
+	if _, err := config.K8sClient.CoreV1().Secrets(targetNamespace).Apply(ctx, apply, metav1.ApplyOptions{
+		FieldManager: secretFieldManager(owner),
+		Force:        true,
+	}); err != nil {
+		return err
+	}
+
+	return reconcileSecretOwnerRef(ctx, targetNamespace, policy.SourceName, ownerRef)
+}
+
+// reconcileExistingSecretType deletes the target secret when its Type no
+// longer matches the source, since Secret.Type is immutable and an Apply
+// can't change it - the next Apply call recreates it with the new Type.
+// existing is the caller's already-fetched Get of the target secret, not
+// re-fetched here, since propagateSecret needs that same Get for the
+// foreign-copy check right before this runs.
+func reconcileExistingSecretType(ctx context.Context, policy types.PropagationPolicy, targetNamespace string, existing *corev1.Secret, secretType corev1.SecretType) error {
+	existingType := existing.Type
+	if existingType == "" {
+		existingType = corev1.SecretTypeOpaque
+	}
+	if existingType == secretType {
+		return nil
+	}
+	if err := config.K8sClient.CoreV1().Secrets(targetNamespace).Delete(ctx, policy.SourceName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// reconcileSecretOwnerRef adds ref to the target secret's OwnerReferences -
+// or refreshes it in place if already present - and evicts any other entry
+// matching ref's APIVersion/Kind/Name but a different UID, left behind when
+// a session is deleted and recreated under the same name. It runs after
+// propagateSecret's Apply, once the secret is known to exist.
+//
+// metadata.ownerReferences is an atomic list, so Server-Side Apply can't
+// merge two single-entry declarations of it the way it merges the map-type
+// fields propagateSecret does manage via Apply: whichever Force Apply lands
+// last would simply replace the whole list with its own one entry. This is
+// a plain Get/Update instead, so two owners' references are merged in
+// application code rather than left to SSA to reconcile a field type it
+// can't reconcile at the granularity this subsystem needs.
+//
+// This deliberately doesn't reuse EnsureOwnerRef: that helper enforces at
+// most one Controller=true reference, which holds for a resource owned by a
+// single AgenticSession but not here - every session that propagates the
+// same secret sets Controller=true on its own reference (see
+// DefaultVertexPropagationPolicy), and a propagated secret is meant to stay
+// alive as long as any one of them does, so multiple controller references
+// on the same copy are the intended state, not a conflict to reject.
+// mergeOwnerRef below is the permissive equivalent used for every
+// propagated resource, Secret or ConfigMap, for the same reason.
+//
+// The Get/Update round trip is a known gap: it's a regular last-write-wins
+// write to the OwnerReferences field, so it can in principle race a
+// concurrent write to an unrelated entry in the same list from another
+// controller. RetryOnConflict narrows that window to the retry loop rather
+// than closing it outright.
+func reconcileSecretOwnerRef(ctx context.Context, targetNamespace, name string, ref metav1.OwnerReference) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := config.K8sClient.CoreV1().Secrets(targetNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !mergeOwnerRef(existing, ref) {
+			return nil
+		}
+		_, err = config.K8sClient.CoreV1().Secrets(targetNamespace).Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// mergeOwnerRef adds ref to obj's OwnerReferences - or refreshes it in
+// place if already present - evicting any other entry matching ref's
+// APIVersion/Kind/Name but a different UID, left behind when a session is
+// deleted and recreated under the same name. Unlike EnsureOwnerRef, it
+// allows more than one Controller=true reference on obj at once: every
+// propagated resource is meant to carry one controlling reference per
+// owning session and stay alive as long as any one of them does, so
+// multiple controllers on the same copy are the intended state here, not a
+// conflict to reject. It returns whether it mutated obj's OwnerReferences.
+func mergeOwnerRef(obj metav1.Object, ref metav1.OwnerReference) bool {
+	existingRefs := obj.GetOwnerReferences()
+
+	changed := false
+	found := false
+	kept := make([]metav1.OwnerReference, 0, len(existingRefs)+1)
+	for _, existingRef := range existingRefs {
+		if existingRef.UID == ref.UID {
+			found = true
+			if !ownerReferenceEqual(existingRef, ref) {
+				changed = true
+				existingRef = ref
+			}
+			kept = append(kept, existingRef)
+			continue
+		}
+		if existingRef.APIVersion == ref.APIVersion && existingRef.Kind == ref.Kind && existingRef.Name == ref.Name {
+			changed = true
+			continue
+		}
+		kept = append(kept, existingRef)
+	}
+	if !found {
+		changed = true
+		kept = append(kept, ref)
+	}
+
+	if changed {
+		obj.SetOwnerReferences(kept)
+	}
+	return changed
+}
+
+// validateSecretData checks that data carries the keys Kubernetes requires
+// for well-known secret types, mirroring the validation the API server
+// itself applies to kubernetes.io/tls and kubernetes.io/dockerconfigjson
+// secrets - we'd rather fail the propagation than write a copy the consuming
+// pod can't actually use.
+func validateSecretData(secretType corev1.SecretType, data map[string][]byte) error {
+	switch secretType {
+	case corev1.SecretTypeTLS:
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if len(data[key]) == 0 {
+				return fmt.Errorf("secret type %q requires a non-empty %q key", secretType, key)
+			}
+		}
+	case corev1.SecretTypeDockerConfigJson:
+		if len(data[corev1.DockerConfigJsonKey]) == 0 {
+			return fmt.Errorf("secret type %q requires a non-empty %q key", secretType, corev1.DockerConfigJsonKey)
+		}
+	}
+	return nil
+}
+
+// propagateConfigMap mirrors the source configmap into targetNamespace,
+// creating it if absent and otherwise keeping its data and owner references
+// up to date. A same-named configmap an administrator created by hand -
+// recognized by the absence of CopiedFromAnnotation - is left untouched
+// entirely rather than adopted.
+func propagateConfigMap(ctx context.Context, policy types.PropagationPolicy, targetNamespace string, owner *unstructured.Unstructured) error {
+	src, err := config.K8sClient.CoreV1().ConfigMaps(policy.SourceNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("source configmap '%s/%s' not found: %w", policy.SourceNamespace, policy.SourceName, err)
+	}
+	data := filterKeys(src.Data, policy.Keys)
+	ownerRef := ownerReferenceFor(owner, policy)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := config.K8sClient.CoreV1().ConfigMaps(targetNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            policy.SourceName,
+					Namespace:       targetNamespace,
+					Annotations:     map[string]string{types.CopiedFromAnnotation: copiedFrom(policy)},
+					OwnerReferences: []metav1.OwnerReference{ownerRef},
+				},
+				Data: data,
+			}
+			_, err := config.K8sClient.CoreV1().ConfigMaps(targetNamespace).Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if isForeignCopy(existing.Annotations) {
+			return nil
+		}
+
+		existing.Data = data
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[types.CopiedFromAnnotation] = copiedFrom(policy)
+		mergeOwnerRef(existing, ownerRef)
+
+		_, err = config.K8sClient.CoreV1().ConfigMaps(targetNamespace).Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func cleanupPropagatedSecret(ctx context.Context, policy types.PropagationPolicy, targetNamespace string) error {
+	secret, err := config.K8sClient.CoreV1().Secrets(targetNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isForeignCopy(secret.Annotations) {
+		return nil
+	}
+	return config.K8sClient.CoreV1().Secrets(targetNamespace).Delete(ctx, policy.SourceName, metav1.DeleteOptions{})
+}
+
+func cleanupPropagatedConfigMap(ctx context.Context, policy types.PropagationPolicy, targetNamespace string) error {
+	cm, err := config.K8sClient.CoreV1().ConfigMaps(targetNamespace).Get(ctx, policy.SourceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isForeignCopy(cm.Annotations) {
+		return nil
+	}
+	return config.K8sClient.CoreV1().ConfigMaps(targetNamespace).Delete(ctx, policy.SourceName, metav1.DeleteOptions{})
+}
@@ -0,0 +1,674 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ownerObj(name, namespace, uid string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("vteam.ambient-code/v1alpha1")
+	obj.SetKind("AgenticSession")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetUID(metav1.UID(uid))
+	return obj
+}
+
+// TestPropagateResource table-drives propagateResource over a mix of Secret
+// and ConfigMap policies, so adding a new propagated resource kind only
+// needs a new case here, not a new test function.
+func TestPropagateResource(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		policy          types.PropagationPolicy
+		sourceSecret    *corev1.Secret
+		sourceConfigMap *corev1.ConfigMap
+		existingSecret  *corev1.Secret
+		existingCM      *corev1.ConfigMap
+		owner           *unstructured.Unstructured
+		targetNamespace string
+		wantErr         bool
+		errContains     string
+		validate        func(t *testing.T, fakeClient *fake.Clientset)
+	}{
+		{
+			name:   "secret - create new copy with controlling owner ref",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-123"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected secret to be created: %v", err)
+				}
+				if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "uid-123" {
+					t.Errorf("OwnerReferences = %+v, want a single ref to uid-123", secret.OwnerReferences)
+				}
+				if secret.OwnerReferences[0].Controller == nil || !*secret.OwnerReferences[0].Controller {
+					t.Error("expected a controlling owner reference")
+				}
+				if secret.Annotations[types.CopiedFromAnnotation] != "operator-ns/ambient-vertex" {
+					t.Errorf("annotation = %v, want operator-ns/ambient-vertex", secret.Annotations[types.CopiedFromAnnotation])
+				}
+			},
+		},
+		{
+			name:   "secret - updates existing copy and adds missing owner ref",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"new":"data"}`)},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "target-ns",
+					Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+				},
+				Data: map[string][]byte{"key.json": []byte(`{"old":"data"}`)},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-456"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, _ := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if string(secret.Data["key.json"]) != `{"new":"data"}` {
+					t.Errorf("Data = %v, want refreshed from source", secret.Data)
+				}
+				if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "uid-456" {
+					t.Errorf("OwnerReferences = %+v, want a single ref to uid-456", secret.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:   "secret - leaves existing owner ref alone when already present",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "target-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "test-session", UID: "uid-789", Controller: boolPtr(true)}},
+				},
+				Data: map[string][]byte{"key.json": []byte(`{"existing":"data"}`)},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-789"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, _ := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if len(secret.OwnerReferences) != 1 {
+					t.Errorf("OwnerReferences = %+v, want the single existing ref kept, not duplicated", secret.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:   "secret - adds a second owner ref alongside another session's",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "target-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "other-session", UID: "uid-other"}},
+				},
+				Data: map[string][]byte{"key.json": []byte(`{"existing":"data"}`)},
+			},
+			owner:           ownerObj("new-session", "target-ns", "uid-new"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, _ := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if len(secret.OwnerReferences) != 2 {
+					t.Errorf("OwnerReferences = %+v, want both sessions' refs kept", secret.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:   "secret - evicts a stale owner ref left by a deleted-and-recreated session",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "target-ns",
+					Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"},
+					// "test-session" was deleted and recreated with a new UID;
+					// this ref is now stale and would otherwise block GC.
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "test-session", UID: "uid-old", Controller: boolPtr(true)}},
+				},
+				Data: map[string][]byte{"key.json": []byte(`{"existing":"data"}`)},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-recreated"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, _ := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "uid-recreated" {
+					t.Errorf("OwnerReferences = %+v, want only the recreated session's ref", secret.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:            "secret - source not found",
+			policy:          types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			owner:           ownerObj("test-session", "target-ns", "uid-999"),
+			targetNamespace: "target-ns",
+			wantErr:         true,
+			errContains:     "not found",
+		},
+		{
+			name:   "secret - nil owner is an error",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			owner:           nil,
+			targetNamespace: "target-ns",
+			wantErr:         true,
+			errContains:     "owner object is nil",
+		},
+		{
+			name:   "secret - Keys filter drops keys not listed",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "registry-creds", Keys: []string{".dockerconfigjson"}},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-creds", Namespace: "operator-ns"},
+				Data: map[string][]byte{
+					".dockerconfigjson": []byte(`{"auths":{}}`),
+					"unrelated-key":     []byte("should not be copied"),
+				},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-filter"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "registry-creds", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected secret to be created: %v", err)
+				}
+				if _, ok := secret.Data["unrelated-key"]; ok {
+					t.Error("Keys filter should have dropped unrelated-key")
+				}
+				if _, ok := secret.Data[".dockerconfigjson"]; !ok {
+					t.Error("Keys filter should have kept .dockerconfigjson")
+				}
+			},
+		},
+		{
+			name:   "configmap - create new copy",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "ca-bundle", Controller: false},
+			sourceConfigMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "operator-ns"},
+				Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-cm"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				cm, err := fakeClient.CoreV1().ConfigMaps("target-ns").Get(ctx, "ca-bundle", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected configmap to be created: %v", err)
+				}
+				if cm.Data["ca.crt"] != "-----BEGIN CERTIFICATE-----" {
+					t.Errorf("Data = %v, want ca.crt copied from source", cm.Data)
+				}
+				if cm.OwnerReferences[0].Controller == nil || *cm.OwnerReferences[0].Controller {
+					t.Error("expected a non-controlling owner reference")
+				}
+			},
+		},
+		{
+			name:   "secret - copies a TLS secret end-to-end",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-tls"},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-tls", Namespace: "operator-ns"},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte("cert-bytes"),
+					corev1.TLSPrivateKeyKey: []byte("key-bytes"),
+				},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-tls"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-tls", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected secret to be created: %v", err)
+				}
+				if secret.Type != corev1.SecretTypeTLS {
+					t.Errorf("Type = %v, want %v", secret.Type, corev1.SecretTypeTLS)
+				}
+				if string(secret.Data[corev1.TLSCertKey]) != "cert-bytes" || string(secret.Data[corev1.TLSPrivateKeyKey]) != "key-bytes" {
+					t.Errorf("Data = %v, want tls.crt/tls.key copied from source", secret.Data)
+				}
+			},
+		},
+		{
+			name:   "secret - rejects a TLS source missing tls.key",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-tls"},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-tls", Namespace: "operator-ns"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert-bytes")},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-tls-bad"),
+			targetNamespace: "target-ns",
+			wantErr:         true,
+			errContains:     "tls.key",
+		},
+		{
+			name:   "secret - delete-and-recreates when the source's type changes",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-tls"},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-tls", Namespace: "operator-ns"},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte("new-cert"),
+					corev1.TLSPrivateKeyKey: []byte("new-key"),
+				},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-tls", Namespace: "target-ns",
+					Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-tls"},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{"key.json": []byte("old-data")},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-retyped"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-tls", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected secret to still exist after retype: %v", err)
+				}
+				if secret.Type != corev1.SecretTypeTLS {
+					t.Errorf("Type = %v, want recreated as %v", secret.Type, corev1.SecretTypeTLS)
+				}
+				if _, ok := secret.Data["key.json"]; ok {
+					t.Error("expected the stale Opaque data to be gone after recreate")
+				}
+			},
+		},
+		{
+			name:   "secret - leaves a hand-created secret of the same name untouched",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true},
+			sourceSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+				Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ambient-vertex", Namespace: "target-ns",
+					// No CopiedFromAnnotation - an administrator created this
+					// by hand, so propagateSecret must not adopt it.
+				},
+				Type: corev1.SecretTypeTLS,
+				Data: map[string][]byte{"hand-created": []byte("do-not-touch")},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-hand-created"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected the hand-created secret to still exist: %v", err)
+				}
+				if secret.Type != corev1.SecretTypeTLS {
+					t.Errorf("Type = %v, want left as %v (not recreated as Opaque)", secret.Type, corev1.SecretTypeTLS)
+				}
+				if string(secret.Data["hand-created"]) != "do-not-touch" {
+					t.Errorf("Data = %v, want the hand-created data left alone", secret.Data)
+				}
+				if len(secret.OwnerReferences) != 0 {
+					t.Errorf("OwnerReferences = %+v, want no owner ref added to a resource we don't own", secret.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:   "configmap - leaves a hand-created configmap of the same name untouched",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "ca-bundle", Controller: true},
+			sourceConfigMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "operator-ns"},
+				Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			},
+			existingCM: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ca-bundle", Namespace: "target-ns",
+					// No CopiedFromAnnotation - left alone.
+				},
+				Data: map[string]string{"hand-created": "do-not-touch"},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-hand-created"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				cm, err := fakeClient.CoreV1().ConfigMaps("target-ns").Get(ctx, "ca-bundle", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected the hand-created configmap to still exist: %v", err)
+				}
+				if cm.Data["hand-created"] != "do-not-touch" {
+					t.Errorf("Data = %v, want the hand-created data left alone", cm.Data)
+				}
+				if len(cm.OwnerReferences) != 0 {
+					t.Errorf("OwnerReferences = %+v, want no owner ref added to a resource we don't own", cm.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:   "configmap - evicts a stale controller ref left by a deleted-and-recreated session",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "ca-bundle", Controller: true},
+			sourceConfigMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "operator-ns"},
+				Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			},
+			existingCM: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ca-bundle", Namespace: "target-ns",
+					Annotations:     map[string]string{types.CopiedFromAnnotation: "operator-ns/ca-bundle"},
+					OwnerReferences: []metav1.OwnerReference{ownerReferenceFor(ownerObj("test-session", "target-ns", "uid-old"), types.PropagationPolicy{Controller: true})},
+				},
+				Data: map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			},
+			owner:           ownerObj("test-session", "target-ns", "uid-new"),
+			targetNamespace: "target-ns",
+			validate: func(t *testing.T, fakeClient *fake.Clientset) {
+				cm, err := fakeClient.CoreV1().ConfigMaps("target-ns").Get(ctx, "ca-bundle", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected configmap to still exist: %v", err)
+				}
+				if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != "uid-new" {
+					t.Errorf("OwnerReferences = %+v, want the stale uid-old ref evicted in favor of uid-new", cm.OwnerReferences)
+				}
+			},
+		},
+		{
+			name:            "configmap - source not found",
+			policy:          types.PropagationPolicy{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "ca-bundle"},
+			owner:           ownerObj("test-session", "target-ns", "uid-cm-missing"),
+			targetNamespace: "target-ns",
+			wantErr:         true,
+			errContains:     "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			if tt.sourceSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.sourceSecret.Namespace).Create(ctx, tt.sourceSecret, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create source secret: %v", err)
+				}
+			}
+			if tt.sourceConfigMap != nil {
+				if _, err := fakeClient.CoreV1().ConfigMaps(tt.sourceConfigMap.Namespace).Create(ctx, tt.sourceConfigMap, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create source configmap: %v", err)
+				}
+			}
+			if tt.existingSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.existingSecret.Namespace).Create(ctx, tt.existingSecret, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create existing secret: %v", err)
+				}
+			}
+			if tt.existingCM != nil {
+				if _, err := fakeClient.CoreV1().ConfigMaps(tt.existingCM.Namespace).Create(ctx, tt.existingCM, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create existing configmap: %v", err)
+				}
+			}
+
+			origK8sClient := config.K8sClient
+			config.K8sClient = fakeClient
+			defer func() { config.K8sClient = origK8sClient }()
+
+			err := propagateResource(ctx, tt.policy, tt.targetNamespace, tt.owner)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("propagateResource() expected error but got nil")
+				}
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("propagateResource() error = %v, want it to contain %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("propagateResource() unexpected error = %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, fakeClient)
+			}
+		})
+	}
+}
+
+// TestCleanupPropagated table-drives cleanupPropagated over Secret and
+// ConfigMap policies, covering the annotation guard that stops it from
+// deleting a resource an administrator created by hand.
+func TestCleanupPropagated(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		policy         types.PropagationPolicy
+		existingSecret *corev1.Secret
+		existingCM     *corev1.ConfigMap
+		wantDeleted    bool
+	}{
+		{
+			name:   "secret - deletes annotated copy",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "test-ns", Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ambient-vertex"}},
+			},
+			wantDeleted: true,
+		},
+		{
+			name:        "secret - already gone is not an error",
+			policy:      types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			wantDeleted: false,
+		},
+		{
+			name:   "secret - leaves unannotated secret alone",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "test-ns"},
+			},
+			wantDeleted: false,
+		},
+		{
+			name:   "secret - annotation key present with a different value still guards a delete",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex"},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "test-ns", Annotations: map[string]string{types.CopiedFromAnnotation: "different-source/different-secret"}},
+			},
+			wantDeleted: true,
+		},
+		{
+			name:   "configmap - deletes annotated copy",
+			policy: types.PropagationPolicy{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "ca-bundle"},
+			existingCM: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "test-ns", Annotations: map[string]string{types.CopiedFromAnnotation: "operator-ns/ca-bundle"}},
+			},
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			if tt.existingSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.existingSecret.Namespace).Create(ctx, tt.existingSecret, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create existing secret: %v", err)
+				}
+			}
+			if tt.existingCM != nil {
+				if _, err := fakeClient.CoreV1().ConfigMaps(tt.existingCM.Namespace).Create(ctx, tt.existingCM, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create existing configmap: %v", err)
+				}
+			}
+
+			origK8sClient := config.K8sClient
+			config.K8sClient = fakeClient
+			defer func() { config.K8sClient = origK8sClient }()
+
+			if err := cleanupPropagated(ctx, tt.policy, "test-ns"); err != nil {
+				t.Fatalf("cleanupPropagated() unexpected error = %v", err)
+			}
+
+			var stillExists bool
+			switch tt.policy.Kind {
+			case types.PropagatedResourceSecret:
+				_, err := fakeClient.CoreV1().Secrets("test-ns").Get(ctx, tt.policy.SourceName, metav1.GetOptions{})
+				stillExists = err == nil
+			case types.PropagatedResourceConfigMap:
+				_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(ctx, tt.policy.SourceName, metav1.GetOptions{})
+				stillExists = err == nil
+			}
+			if tt.wantDeleted == stillExists {
+				t.Errorf("resource exists = %v, want deleted = %v", stillExists, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+// TestPropagateAll_AggregatesErrors confirms PropagateAll attempts every
+// policy and joins the failures instead of stopping at the first one.
+func TestPropagateAll_AggregatesErrors(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset()
+	origK8sClient := config.K8sClient
+	config.K8sClient = fakeClient
+	defer func() { config.K8sClient = origK8sClient }()
+
+	policies := []types.PropagationPolicy{
+		{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "missing-secret"},
+		{Kind: types.PropagatedResourceConfigMap, SourceNamespace: "operator-ns", SourceName: "missing-configmap"},
+	}
+
+	err := PropagateAll(ctx, policies, "target-ns", ownerObj("test-session", "target-ns", "uid-agg"))
+	if err == nil {
+		t.Fatal("PropagateAll() expected error but got nil")
+	}
+	if !contains(err.Error(), "missing-secret") || !contains(err.Error(), "missing-configmap") {
+		t.Errorf("PropagateAll() error = %v, want both failures joined", err)
+	}
+}
+
+// TestPropagateSecret_IdempotentReapply confirms Server-Side Apply lets the
+// same session reconcile the same secret repeatedly without piling up
+// duplicate owner references or erroring on the second Apply.
+func TestPropagateSecret_IdempotentReapply(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+		Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+	})
+	origK8sClient := config.K8sClient
+	config.K8sClient = fakeClient
+	defer func() { config.K8sClient = origK8sClient }()
+
+	policy := types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true}
+	owner := ownerObj("test-session", "target-ns", "uid-idempotent")
+
+	for i := 0; i < 3; i++ {
+		if err := propagateResource(ctx, policy, "target-ns", owner); err != nil {
+			t.Fatalf("propagateResource() call %d unexpected error = %v", i, err)
+		}
+	}
+
+	secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected secret to exist: %v", err)
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "uid-idempotent" {
+		t.Errorf("OwnerReferences = %+v, want a single ref re-applied idempotently", secret.OwnerReferences)
+	}
+	if string(secret.Data["key.json"]) != `{"test":"data"}` {
+		t.Errorf("Data = %v, want unchanged across re-applies", secret.Data)
+	}
+}
+
+// TestPropagateSecret_ConcurrentOwnersBothPersist propagates the same source
+// secret into the same target namespace from two goroutines with distinct
+// owner UIDs, standing in for two sessions reconciling the same secret at
+// once. reconcileSecretOwnerRef's read-merge-write must let both owner
+// references land rather than one overwriting the other.
+func TestPropagateSecret_ConcurrentOwnersBothPersist(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ambient-vertex", Namespace: "operator-ns"},
+		Data:       map[string][]byte{"key.json": []byte(`{"test":"data"}`)},
+	})
+	origK8sClient := config.K8sClient
+	config.K8sClient = fakeClient
+	defer func() { config.K8sClient = origK8sClient }()
+
+	policy := types.PropagationPolicy{Kind: types.PropagatedResourceSecret, SourceNamespace: "operator-ns", SourceName: "ambient-vertex", Controller: true}
+	owners := []*unstructured.Unstructured{
+		ownerObj("session-a", "target-ns", "uid-a"),
+		ownerObj("session-b", "target-ns", "uid-b"),
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(owners))
+	for i, owner := range owners {
+		wg.Add(1)
+		go func(i int, owner *unstructured.Unstructured) {
+			defer wg.Done()
+			errs[i] = propagateResource(ctx, policy, "target-ns", owner)
+		}(i, owner)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("propagateResource() goroutine %d unexpected error = %v", i, err)
+		}
+	}
+
+	secret, err := fakeClient.CoreV1().Secrets("target-ns").Get(ctx, "ambient-vertex", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected secret to exist: %v", err)
+	}
+	if len(secret.OwnerReferences) != 2 {
+		t.Fatalf("OwnerReferences = %+v, want both concurrent owners present", secret.OwnerReferences)
+	}
+	gotUIDs := map[string]bool{}
+	for _, ref := range secret.OwnerReferences {
+		gotUIDs[string(ref.UID)] = true
+	}
+	if !gotUIDs["uid-a"] || !gotUIDs["uid-b"] {
+		t.Errorf("OwnerReferences = %+v, want uid-a and uid-b both present", secret.OwnerReferences)
+	}
+	if string(secret.Data["key.json"]) != `{"test":"data"}` {
+		t.Errorf("Data = %v, want the source data intact after both applies", secret.Data)
+	}
+}
+
+// contains reports whether s contains substr.
+func contains(s, substr string) bool {
+	if len(substr) == 0 {
+		return true
+	}
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
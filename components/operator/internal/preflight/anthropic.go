@@ -0,0 +1,65 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// anthropicAPIKeyPattern matches the "sk-ant-" prefix Anthropic assigns to
+// every direct API key, as opposed to a Vertex/Bedrock credential pasted into
+// the wrong secret by mistake.
+var anthropicAPIKeyPattern = regexp.MustCompile(`^sk-ant-`)
+
+// AnthropicValidator checks the operator's direct Anthropic API
+// configuration: the ambient-anthropic secret and its api_key field.
+type AnthropicValidator struct {
+	OperatorNamespace string
+}
+
+// NewAnthropicValidator returns a Validator for the Anthropic direct API.
+func NewAnthropicValidator(operatorNamespace string) *AnthropicValidator {
+	return &AnthropicValidator{OperatorNamespace: operatorNamespace}
+}
+
+// Name implements Validator.
+func (v *AnthropicValidator) Name() string { return "anthropic" }
+
+// Enabled implements Validator. Anthropic direct is the default backend: it
+// is used whenever none of Vertex AI, Bedrock, or Azure OpenAI has been
+// selected.
+func (v *AnthropicValidator) Enabled() bool {
+	return os.Getenv("CLAUDE_CODE_USE_VERTEX") != "1" &&
+		os.Getenv("CLAUDE_CODE_USE_BEDROCK") != "1" &&
+		os.Getenv("CLAUDE_CODE_USE_AZURE") != "1"
+}
+
+// Validate implements Validator.
+func (v *AnthropicValidator) Validate(ctx context.Context) error {
+	secret, err := config.K8sClient.CoreV1().Secrets(v.OperatorNamespace).Get(
+		ctx,
+		types.AmbientAnthropicSecretName,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("secret '%s' not found in namespace '%s': %w\n"+
+			"Please create the secret with: kubectl create secret generic %s --from-literal=api_key=sk-ant-... -n %s",
+			types.AmbientAnthropicSecretName, v.OperatorNamespace, err, types.AmbientAnthropicSecretName, v.OperatorNamespace)
+	}
+
+	apiKey, ok := secret.Data["api_key"]
+	if !ok || len(apiKey) == 0 {
+		return fmt.Errorf("secret '%s' missing 'api_key' key - ensure secret was created with --from-literal=api_key=sk-ant-...", types.AmbientAnthropicSecretName)
+	}
+
+	if !anthropicAPIKeyPattern.Match(apiKey) {
+		return fmt.Errorf("secret '%s' key 'api_key' does not look like an Anthropic API key (expected it to start with 'sk-ant-')", types.AmbientAnthropicSecretName)
+	}
+
+	return nil
+}
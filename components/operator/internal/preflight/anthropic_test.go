@@ -0,0 +1,128 @@
+package preflight
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnthropicValidator_Enabled(t *testing.T) {
+	envKeys := []string{"CLAUDE_CODE_USE_VERTEX", "CLAUDE_CODE_USE_BEDROCK", "CLAUDE_CODE_USE_AZURE"}
+	defer func() {
+		for _, key := range envKeys {
+			os.Unsetenv(key)
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		want    bool
+	}{
+		{name: "no other backend selected", want: true},
+		{name: "vertex selected", envVars: map[string]string{"CLAUDE_CODE_USE_VERTEX": "1"}, want: false},
+		{name: "bedrock selected", envVars: map[string]string{"CLAUDE_CODE_USE_BEDROCK": "1"}, want: false},
+		{name: "azure selected", envVars: map[string]string{"CLAUDE_CODE_USE_AZURE": "1"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range envKeys {
+				os.Unsetenv(key)
+			}
+			for key, val := range tt.envVars {
+				os.Setenv(key, val)
+			}
+
+			v := NewAnthropicValidator("test-namespace")
+			if got := v.Enabled(); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnthropicValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		existingSecret *corev1.Secret
+		wantErr        bool
+		errContains    string
+	}{
+		{
+			name: "success - valid key",
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.AmbientAnthropicSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{"api_key": []byte("sk-ant-abc123")},
+			},
+		},
+		{
+			name:        "error - secret missing",
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name: "error - missing api_key",
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.AmbientAnthropicSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{},
+			},
+			wantErr:     true,
+			errContains: "missing 'api_key'",
+		},
+		{
+			name: "error - key does not look like an Anthropic key",
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.AmbientAnthropicSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{"api_key": []byte("AKIAABCDEFGH")},
+			},
+			wantErr:     true,
+			errContains: "does not look like an Anthropic API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			if tt.existingSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.existingSecret.Namespace).Create(
+					context.Background(), tt.existingSecret, metav1.CreateOptions{},
+				); err != nil {
+					t.Fatalf("failed to create fake secret: %v", err)
+				}
+			}
+			origK8sClient := config.K8sClient
+			config.K8sClient = fakeClient
+			defer func() { config.K8sClient = origK8sClient }()
+
+			v := NewAnthropicValidator("test-namespace")
+			err := v.Validate(context.Background())
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() expected error but got nil")
+				}
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %v, want error containing %q", err, tt.errContains)
+				}
+			} else if err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
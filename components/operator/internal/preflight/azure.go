@@ -0,0 +1,64 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AzureOpenAIValidator checks the operator's Azure OpenAI configuration: the
+// endpoint URL, deployment name, and the ambient-azure-openai secret holding
+// the API key.
+type AzureOpenAIValidator struct {
+	OperatorNamespace string
+}
+
+// NewAzureOpenAIValidator returns a Validator for Azure OpenAI.
+func NewAzureOpenAIValidator(operatorNamespace string) *AzureOpenAIValidator {
+	return &AzureOpenAIValidator{OperatorNamespace: operatorNamespace}
+}
+
+// Name implements Validator.
+func (v *AzureOpenAIValidator) Name() string { return "azure-openai" }
+
+// Enabled implements Validator. Azure OpenAI is selected with
+// CLAUDE_CODE_USE_AZURE=1.
+func (v *AzureOpenAIValidator) Enabled() bool {
+	return os.Getenv("CLAUDE_CODE_USE_AZURE") == "1"
+}
+
+// Validate implements Validator.
+func (v *AzureOpenAIValidator) Validate(ctx context.Context) error {
+	if os.Getenv("AZURE_OPENAI_DEPLOYMENT") == "" {
+		return fmt.Errorf("CLAUDE_CODE_USE_AZURE=1 but AZURE_OPENAI_DEPLOYMENT is not set")
+	}
+
+	endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+	if endpoint == "" {
+		return fmt.Errorf("CLAUDE_CODE_USE_AZURE=1 but AZURE_OPENAI_ENDPOINT is not set")
+	}
+	if err := validateHTTPSURLField("AZURE_OPENAI_ENDPOINT", endpoint); err != nil {
+		return err
+	}
+
+	secret, err := config.K8sClient.CoreV1().Secrets(v.OperatorNamespace).Get(
+		ctx,
+		types.AmbientAzureSecretName,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("secret '%s' not found in namespace '%s': %w\n"+
+			"Please create the secret with: kubectl create secret generic %s --from-literal=api_key=... -n %s",
+			types.AmbientAzureSecretName, v.OperatorNamespace, err, types.AmbientAzureSecretName, v.OperatorNamespace)
+	}
+
+	if apiKey, ok := secret.Data["api_key"]; !ok || len(apiKey) == 0 {
+		return fmt.Errorf("secret '%s' missing 'api_key' key - ensure secret was created with --from-literal=api_key=...", types.AmbientAzureSecretName)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package preflight
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAzureOpenAIValidator_Validate(t *testing.T) {
+	envKeys := []string{"AZURE_OPENAI_DEPLOYMENT", "AZURE_OPENAI_ENDPOINT"}
+	defer func() {
+		for _, key := range envKeys {
+			os.Unsetenv(key)
+		}
+	}()
+
+	tests := []struct {
+		name           string
+		envVars        map[string]string
+		existingSecret *corev1.Secret
+		wantErr        bool
+		errContains    string
+	}{
+		{
+			name: "success - all valid",
+			envVars: map[string]string{
+				"AZURE_OPENAI_DEPLOYMENT": "gpt-4",
+				"AZURE_OPENAI_ENDPOINT":   "https://test.openai.azure.com",
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.AmbientAzureSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{"api_key": []byte("test-key")},
+			},
+		},
+		{
+			name:        "error - missing deployment",
+			envVars:     map[string]string{"AZURE_OPENAI_ENDPOINT": "https://test.openai.azure.com"},
+			wantErr:     true,
+			errContains: "AZURE_OPENAI_DEPLOYMENT is not set",
+		},
+		{
+			name:        "error - missing endpoint",
+			envVars:     map[string]string{"AZURE_OPENAI_DEPLOYMENT": "gpt-4"},
+			wantErr:     true,
+			errContains: "AZURE_OPENAI_ENDPOINT is not set",
+		},
+		{
+			name: "error - endpoint not https",
+			envVars: map[string]string{
+				"AZURE_OPENAI_DEPLOYMENT": "gpt-4",
+				"AZURE_OPENAI_ENDPOINT":   "http://test.openai.azure.com",
+			},
+			wantErr:     true,
+			errContains: "AZURE_OPENAI_ENDPOINT",
+		},
+		{
+			name: "error - secret missing",
+			envVars: map[string]string{
+				"AZURE_OPENAI_DEPLOYMENT": "gpt-4",
+				"AZURE_OPENAI_ENDPOINT":   "https://test.openai.azure.com",
+			},
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name: "error - missing api_key",
+			envVars: map[string]string{
+				"AZURE_OPENAI_DEPLOYMENT": "gpt-4",
+				"AZURE_OPENAI_ENDPOINT":   "https://test.openai.azure.com",
+			},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.AmbientAzureSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{},
+			},
+			wantErr:     true,
+			errContains: "missing 'api_key'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range envKeys {
+				os.Unsetenv(key)
+			}
+			for key, val := range tt.envVars {
+				os.Setenv(key, val)
+			}
+
+			fakeClient := fake.NewSimpleClientset()
+			if tt.existingSecret != nil {
+				if _, err := fakeClient.CoreV1().Secrets(tt.existingSecret.Namespace).Create(
+					context.Background(), tt.existingSecret, metav1.CreateOptions{},
+				); err != nil {
+					t.Fatalf("failed to create fake secret: %v", err)
+				}
+			}
+			origK8sClient := config.K8sClient
+			config.K8sClient = fakeClient
+			defer func() { config.K8sClient = origK8sClient }()
+
+			v := NewAzureOpenAIValidator("test-namespace")
+			err := v.Validate(context.Background())
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() expected error but got nil")
+				}
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %v, want error containing %q", err, tt.errContains)
+				}
+			} else if err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// irsaRoleARNAnnotation is the annotation EKS's Pod Identity Webhook looks
+// for on a ServiceAccount to grant it an IAM role via Workload Identity -
+// AWS's equivalent of GCP's Workload Identity Federation.
+const irsaRoleARNAnnotation = "eks.amazonaws.com/role-arn"
+
+// BedrockValidator checks the operator's AWS Bedrock configuration: the
+// target region and a way to authenticate, either static AWS credentials or
+// an IRSA role bound to the operator's own ServiceAccount.
+type BedrockValidator struct {
+	OperatorNamespace string
+}
+
+// NewBedrockValidator returns a Validator for AWS Bedrock.
+func NewBedrockValidator(operatorNamespace string) *BedrockValidator {
+	return &BedrockValidator{OperatorNamespace: operatorNamespace}
+}
+
+// Name implements Validator.
+func (v *BedrockValidator) Name() string { return "bedrock" }
+
+// Enabled implements Validator. AWS Bedrock is selected with
+// CLAUDE_CODE_USE_BEDROCK=1.
+func (v *BedrockValidator) Enabled() bool {
+	return os.Getenv("CLAUDE_CODE_USE_BEDROCK") == "1"
+}
+
+// Validate implements Validator.
+func (v *BedrockValidator) Validate(ctx context.Context) error {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		return fmt.Errorf("CLAUDE_CODE_USE_BEDROCK=1 but AWS_REGION is not set")
+	}
+
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "" {
+		return nil
+	}
+
+	sa, err := config.K8sClient.CoreV1().ServiceAccounts(v.OperatorNamespace).Get(
+		ctx,
+		types.OperatorServiceAccountName,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("no AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY set and operator ServiceAccount '%s' not found in namespace '%s' to check for an IRSA role: %w",
+			types.OperatorServiceAccountName, v.OperatorNamespace, err)
+	}
+
+	if roleARN := sa.Annotations[irsaRoleARNAnnotation]; roleARN == "" {
+		return fmt.Errorf("no AWS credentials found: set AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY, or annotate ServiceAccount '%s' with '%s' for IRSA",
+			types.OperatorServiceAccountName, irsaRoleARNAnnotation)
+	}
+
+	return nil
+}
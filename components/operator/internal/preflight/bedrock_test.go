@@ -0,0 +1,118 @@
+package preflight
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBedrockValidator_Validate(t *testing.T) {
+	envKeys := []string{"AWS_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}
+	defer func() {
+		for _, key := range envKeys {
+			os.Unsetenv(key)
+		}
+	}()
+
+	tests := []struct {
+		name                string
+		envVars             map[string]string
+		existingServiceAcct *corev1.ServiceAccount
+		wantErr             bool
+		errContains         string
+	}{
+		{
+			name: "success - static credentials",
+			envVars: map[string]string{
+				"AWS_REGION":            "us-east-1",
+				"AWS_ACCESS_KEY_ID":     "AKIAABCDEFGH",
+				"AWS_SECRET_ACCESS_KEY": "secret",
+			},
+		},
+		{
+			name:        "error - missing region",
+			wantErr:     true,
+			errContains: "AWS_REGION is not set",
+		},
+		{
+			name: "success - IRSA role annotation",
+			envVars: map[string]string{
+				"AWS_REGION": "us-east-1",
+			},
+			existingServiceAcct: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.OperatorServiceAccountName,
+					Namespace: "test-namespace",
+					Annotations: map[string]string{
+						irsaRoleARNAnnotation: "arn:aws:iam::123456789012:role/ambient-code-operator",
+					},
+				},
+			},
+		},
+		{
+			name: "error - service account not found",
+			envVars: map[string]string{
+				"AWS_REGION": "us-east-1",
+			},
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name: "error - service account missing IRSA annotation",
+			envVars: map[string]string{
+				"AWS_REGION": "us-east-1",
+			},
+			existingServiceAcct: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      types.OperatorServiceAccountName,
+					Namespace: "test-namespace",
+				},
+			},
+			wantErr:     true,
+			errContains: "no AWS credentials found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range envKeys {
+				os.Unsetenv(key)
+			}
+			for key, val := range tt.envVars {
+				os.Setenv(key, val)
+			}
+
+			fakeClient := fake.NewSimpleClientset()
+			if tt.existingServiceAcct != nil {
+				if _, err := fakeClient.CoreV1().ServiceAccounts(tt.existingServiceAcct.Namespace).Create(
+					context.Background(), tt.existingServiceAcct, metav1.CreateOptions{},
+				); err != nil {
+					t.Fatalf("failed to create fake service account: %v", err)
+				}
+			}
+			origK8sClient := config.K8sClient
+			config.K8sClient = fakeClient
+			defer func() { config.K8sClient = origK8sClient }()
+
+			v := NewBedrockValidator("test-namespace")
+			err := v.Validate(context.Background())
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() expected error but got nil")
+				}
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %v, want error containing %q", err, tt.errContains)
+				}
+			} else if err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
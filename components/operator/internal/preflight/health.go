@@ -0,0 +1,33 @@
+package preflight
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HealthzHandler is a liveness probe: it always returns 200 once the
+// process is serving HTTP, regardless of credential validity, since a bad
+// secret doesn't mean the operator process itself needs restarting.
+func HealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// ReadyzHandler is a readiness probe backed by Watcher.LastResult, so the
+// pod is taken out of service the moment the ambient-vertex secret is
+// rotated to something invalid or deleted, instead of only at the next
+// restart.
+func (w *Watcher) ReadyzHandler() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		result := w.LastResult()
+		if !result.Valid {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(rw, "not ready: %s\n", result.Reason)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		fmt.Fprintln(rw, "ready")
+	}
+}
@@ -0,0 +1,51 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Validator checks that one LLM backend is correctly configured before the
+// operator starts handing out agentic sessions. Each supported backend
+// (Vertex AI, Anthropic direct, AWS Bedrock, Azure OpenAI) implements this
+// independently so they can be validated - and extended - without touching
+// the others.
+type Validator interface {
+	// Name identifies the validator in aggregated error output.
+	Name() string
+	// Enabled reports whether this backend is selected, typically via a
+	// CLAUDE_CODE_USE_* environment variable. Disabled validators are
+	// skipped by RunAll rather than failing.
+	Enabled() bool
+	// Validate performs the backend-specific configuration checks.
+	Validate(ctx context.Context) error
+}
+
+// RunAll runs every enabled Validator concurrently and aggregates their
+// results with errors.Join, so administrators see every misconfiguration in
+// one pass instead of fixing them one operator restart at a time.
+func RunAll(ctx context.Context, validators ...Validator) error {
+	var enabled []Validator
+	for _, v := range validators {
+		if v.Enabled() {
+			enabled = append(enabled, v)
+		}
+	}
+
+	errs := make([]error, len(enabled))
+	var wg sync.WaitGroup
+	for i, v := range enabled {
+		wg.Add(1)
+		go func(i int, v Validator) {
+			defer wg.Done()
+			if err := v.Validate(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", v.Name(), err)
+			}
+		}(i, v)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
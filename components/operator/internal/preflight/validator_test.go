@@ -0,0 +1,58 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeValidator is a minimal Validator for exercising RunAll's enable
+// filtering and error aggregation without touching any real backend.
+type fakeValidator struct {
+	name    string
+	enabled bool
+	err     error
+}
+
+func (f *fakeValidator) Name() string    { return f.name }
+func (f *fakeValidator) Enabled() bool   { return f.enabled }
+func (f *fakeValidator) Validate(ctx context.Context) error {
+	return f.err
+}
+
+func TestRunAll(t *testing.T) {
+	t.Run("skips disabled validators", func(t *testing.T) {
+		disabled := &fakeValidator{name: "disabled", enabled: false, err: errors.New("should never run")}
+		enabled := &fakeValidator{name: "enabled", enabled: true}
+
+		if err := RunAll(context.Background(), disabled, enabled); err != nil {
+			t.Errorf("RunAll() unexpected error = %v", err)
+		}
+	})
+
+	t.Run("aggregates errors from every enabled validator", func(t *testing.T) {
+		vertex := &fakeValidator{name: "vertex", enabled: true, err: errors.New("bad vertex config")}
+		anthropic := &fakeValidator{name: "anthropic", enabled: true, err: errors.New("bad anthropic config")}
+		bedrock := &fakeValidator{name: "bedrock", enabled: false, err: errors.New("should never run")}
+
+		err := RunAll(context.Background(), vertex, anthropic, bedrock)
+		if err == nil {
+			t.Fatal("RunAll() expected error but got nil")
+		}
+		if !contains(err.Error(), "vertex: bad vertex config") {
+			t.Errorf("RunAll() error = %v, want it to contain the vertex failure", err)
+		}
+		if !contains(err.Error(), "anthropic: bad anthropic config") {
+			t.Errorf("RunAll() error = %v, want it to contain the anthropic failure", err)
+		}
+		if contains(err.Error(), "should never run") {
+			t.Errorf("RunAll() error = %v, ran a disabled validator", err)
+		}
+	})
+
+	t.Run("no enabled validators is not an error", func(t *testing.T) {
+		if err := RunAll(context.Background()); err != nil {
+			t.Errorf("RunAll() unexpected error = %v", err)
+		}
+	})
+}
@@ -2,18 +2,43 @@ package preflight
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
+	"net/mail"
+	"net/url"
 	"os"
+	"regexp"
 
-	"ambient-code-operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// serviceAccountEmailSuffix is the domain suffix Google assigns to every
+// service-account identity; a client_email without it cannot be a valid key.
+const serviceAccountEmailSuffix = "iam.gserviceaccount.com"
+
+// privateKeyIDPattern matches the lowercase hex key-id Google issues when a
+// service-account key is created (a SHA-1 fingerprint of the public key).
+var privateKeyIDPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 // ValidateVertexConfig validates Vertex AI configuration at operator startup
 func ValidateVertexConfig(operatorNamespace string) error {
+	_, err := ValidateVertexConfigWithResult(operatorNamespace)
+	return err
+}
+
+// ValidateVertexConfigWithResult does the same validation as
+// ValidateVertexConfig, but also returns which authentication mode the
+// secret uses so downstream code (e.g. the agentic session pod spec) can
+// decide whether to mount the secret or a projected Workload Identity
+// Federation token volume.
+func ValidateVertexConfigWithResult(operatorNamespace string) (*VertexSecretValidationResult, error) {
 	log.Printf("Vertex AI mode enabled - validating configuration...")
 
 	// Check required environment variables
@@ -25,33 +50,63 @@ func ValidateVertexConfig(operatorNamespace string) error {
 
 	for name, value := range requiredEnvVars {
 		if value == "" {
-			return fmt.Errorf("CLAUDE_CODE_USE_VERTEX=1 but %s is not set", name)
+			return nil, fmt.Errorf("CLAUDE_CODE_USE_VERTEX=1 but %s is not set", name)
 		}
 		log.Printf("  %s: %s", name, value)
 	}
 
 	// Check that ambient-vertex secret exists in operator namespace
-	secretName := "ambient-vertex"
+	secretName := types.AmbientVertexSecretName
 	secret, err := config.K8sClient.CoreV1().Secrets(operatorNamespace).Get(
 		context.TODO(),
 		secretName,
 		metav1.GetOptions{},
 	)
 	if err != nil {
-		return fmt.Errorf("secret '%s' not found in namespace '%s': %w\n"+
+		return nil, fmt.Errorf("secret '%s' not found in namespace '%s': %w\n"+
 			"Please create the secret with: kubectl create secret generic %s --from-file=key.json=/path/to/service-account.json -n %s",
 			secretName, operatorNamespace, err, secretName, operatorNamespace)
 	}
 	log.Printf("  Secret '%s' found in namespace '%s'", secretName, operatorNamespace)
 
+	// A missing or unparseable key.json is detected as a service-account key
+	// and reported by validateVertexSecret below.
+	var keyData map[string]any
+	_ = json.Unmarshal(secret.Data["key.json"], &keyData)
+	authMode := detectVertexAuthMode(keyData)
+	result := &VertexSecretValidationResult{AuthMode: authMode}
+
+	if authMode == types.AmbientVertexAuthModeWorkloadIdentityFederation {
+		log.Printf("  Secret uses Workload Identity Federation")
+		if err := validateWorkloadIdentitySecret(secret); err != nil {
+			return nil, fmt.Errorf("secret '%s' is invalid: %w", secretName, err)
+		}
+		log.Printf("  Workload Identity Federation credential validated")
+		log.Printf("Vertex AI configuration validated successfully")
+		return result, nil
+	}
+
 	// Validate secret structure
 	if err := validateVertexSecret(secret, os.Getenv("ANTHROPIC_VERTEX_PROJECT_ID")); err != nil {
-		return fmt.Errorf("secret '%s' is invalid: %w", secretName, err)
+		return nil, fmt.Errorf("secret '%s' is invalid: %w", secretName, err)
 	}
 	log.Printf("  Secret structure validated")
 
+	// A secret can be perfectly well-formed and still fail to authenticate -
+	// mint a real token and probe the Vertex AI endpoint to catch that before
+	// the first agentic session tries to use it.
+	if liveAuthProbeEnabled() {
+		if err := runLiveAuthProbe(context.TODO(), secret.Data["key.json"],
+			os.Getenv("ANTHROPIC_VERTEX_PROJECT_ID"), os.Getenv("CLOUD_ML_REGION")); err != nil {
+			return nil, fmt.Errorf("live authentication probe against Vertex AI failed: %w", err)
+		}
+		log.Printf("  Live authentication probe succeeded")
+	} else {
+		log.Printf("  Skipping live authentication probe (%s=%s)", liveCheckEnvVar, os.Getenv(liveCheckEnvVar))
+	}
+
 	log.Printf("Vertex AI configuration validated successfully")
-	return nil
+	return result, nil
 }
 
 // validateVertexSecret validates the structure of a Vertex AI secret
@@ -94,5 +149,105 @@ func validateVertexSecret(secret *corev1.Secret, expectedProjectID string) error
 		}
 	}
 
+	// Beyond shape-checking, verify the key material and identity fields are
+	// actually usable so a misconfigured secret fails at startup rather than
+	// on the first live API call. Accumulate every problem instead of
+	// stopping at the first one so operators can fix them all in one pass.
+	var errs []error
+	if err := validatePrivateKey(data["private_key"]); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateClientEmail(data["client_email"]); err != nil {
+		errs = append(errs, err)
+	}
+	for _, field := range []string{"auth_uri", "token_uri", "auth_provider_x509_cert_url", "client_x509_cert_url"} {
+		if err := validateHTTPSURLField(field, data[field]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validatePrivateKeyID(data["private_key_id"]); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validatePrivateKey PEM-decodes the private_key field and parses the DER
+// block to confirm it parses as a PKCS#8 private key (of any algorithm) or
+// a PKCS#1 RSA private key, not just a string that looks like one.
+func validatePrivateKey(raw any) error {
+	keyStr, ok := raw.(string)
+	if !ok || keyStr == "" {
+		return fmt.Errorf("'private_key' is missing or not a string")
+	}
+
+	block, _ := pem.Decode([]byte(keyStr))
+	if block == nil {
+		return fmt.Errorf("'private_key' is not valid PEM data")
+	}
+	if block.Type != "PRIVATE KEY" && block.Type != "RSA PRIVATE KEY" {
+		return fmt.Errorf("'private_key' PEM block has type '%s', expected 'PRIVATE KEY' or 'RSA PRIVATE KEY'", block.Type)
+	}
+
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return nil
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return nil
+	}
+	return fmt.Errorf("'private_key' could not be parsed as a PKCS#8 or PKCS#1 private key")
+}
+
+// validateClientEmail checks that client_email is both a well-formed email
+// address and an actual service-account identity, not a human user.
+func validateClientEmail(raw any) error {
+	emailStr, ok := raw.(string)
+	if !ok || emailStr == "" {
+		return fmt.Errorf("'client_email' is missing or not a string")
+	}
+	if _, err := mail.ParseAddress(emailStr); err != nil {
+		return fmt.Errorf("'client_email' is not a valid email address: %w", err)
+	}
+	if !regexp.MustCompile(`@[^@]*\.` + regexp.QuoteMeta(serviceAccountEmailSuffix) + `$`).MatchString(emailStr) {
+		return fmt.Errorf("'client_email' (%s) does not end in .%s - doesn't look like a service account", emailStr, serviceAccountEmailSuffix)
+	}
+	return nil
+}
+
+// validateHTTPSURLField validates that the named field, when present, parses
+// as an absolute https:// URL. Google service-account keys ship these fields
+// pointing at GCP's OAuth/token endpoints, so anything else indicates a
+// hand-edited or truncated key.
+func validateHTTPSURLField(field string, raw any) error {
+	if raw == nil {
+		return nil
+	}
+	urlStr, ok := raw.(string)
+	if !ok || urlStr == "" {
+		return fmt.Errorf("'%s' is not a string", field)
+	}
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid URL: %w", field, err)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("'%s' must use https, got '%s'", field, parsed.Scheme)
+	}
+	return nil
+}
+
+// validatePrivateKeyID confirms private_key_id, when present, looks like the
+// lowercase hex key fingerprint Google issues rather than arbitrary text.
+func validatePrivateKeyID(raw any) error {
+	if raw == nil {
+		return nil
+	}
+	idStr, ok := raw.(string)
+	if !ok || idStr == "" {
+		return fmt.Errorf("'private_key_id' is not a string")
+	}
+	if !privateKeyIDPattern.MatchString(idStr) {
+		return fmt.Errorf("'private_key_id' (%s) is not a lowercase hex string of the expected length", idStr)
+	}
 	return nil
 }
@@ -0,0 +1,96 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// vertexAICloudPlatformScope is the OAuth2 scope required to call the
+	// Vertex AI regional endpoints.
+	vertexAICloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// liveCheckEnvVar gates the live authentication probe. It defaults to on;
+	// unit tests set it to "0" so they never make outbound network calls.
+	liveCheckEnvVar = "VTEAM_PREFLIGHT_LIVE_CHECK"
+
+	liveProbeTimeout = 10 * time.Second
+)
+
+// liveProbeHTTPClient is the client used to reach the Vertex AI endpoint.
+// Tests swap it out for one pointed at an httptest.Server.
+var liveProbeHTTPClient = http.DefaultClient
+
+// vertexAIEndpoint builds the regional Vertex AI endpoint to probe. It is a
+// package variable so tests can redirect it at an httptest.Server instead of
+// the real googleapis.com host.
+var vertexAIEndpoint = func(region, projectID string) string {
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/anthropic/models",
+		region, projectID, region)
+}
+
+// liveAuthProbeEnabled reports whether the live GCP authentication probe
+// should run. Defaults to on; set VTEAM_PREFLIGHT_LIVE_CHECK=0 to skip it
+// (used by unit tests, and available for air-gapped environments).
+func liveAuthProbeEnabled() bool {
+	val := os.Getenv(liveCheckEnvVar)
+	if val == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// runLiveAuthProbe exchanges the service-account key for an OAuth2 token and
+// issues a lightweight authenticated request against the Vertex AI regional
+// endpoint. This catches keys that parse correctly but can't actually
+// authenticate - wrong project, a revoked key, or a service account missing
+// the aiplatform.user role - at operator startup instead of on first use.
+func runLiveAuthProbe(ctx context.Context, keyJSON []byte, projectID, region string) error {
+	creds, err := google.CredentialsFromJSON(ctx, keyJSON, vertexAICloudPlatformScope)
+	if err != nil {
+		return fmt.Errorf("failed to load credentials from key.json: %w", err)
+	}
+
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to mint an access token from the service account key: %w", err)
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, liveProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, vertexAIEndpoint(region, projectID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build Vertex AI probe request: %w", err)
+	}
+	token.SetAuthHeader(req)
+
+	resp, err := liveProbeHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error reaching Vertex AI endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("service account lacks aiplatform.user role (HTTP %d)", resp.StatusCode)
+	case http.StatusNotFound:
+		return fmt.Errorf("region/project mismatch (HTTP 404) for project '%s' region '%s'", projectID, region)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response from Vertex AI endpoint (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+}
@@ -0,0 +1,156 @@
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeServiceAccountKeyJSON is enough of a service-account key shape for
+// google.CredentialsFromJSON to build a token source around a fake token
+// endpoint - it doesn't need to be a real, authorizable key.
+func fakeServiceAccountKeyJSON(tokenURL string) []byte {
+	return []byte(`{
+		"type": "service_account",
+		"project_id": "test-project",
+		"private_key_id": "0123456789abcdef0123456789abcdef01234567",
+		"private_key": "` + testServiceAccountPrivateKeyJSON + `",
+		"client_email": "test@test-project.iam.gserviceaccount.com",
+		"client_id": "123456789",
+		"auth_uri": "https://accounts.google.com/o/oauth2/auth",
+		"token_uri": "` + tokenURL + `"
+	}`)
+}
+
+func newTokenServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"fake-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+}
+
+func TestRunLiveAuthProbe(t *testing.T) {
+	tokenServer := newTokenServer(t)
+	defer tokenServer.Close()
+
+	tests := []struct {
+		name          string
+		vertexHandler http.HandlerFunc
+		wantErr       bool
+		errContains   string
+	}{
+		{
+			name: "success - 200 OK",
+			vertexHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+		{
+			name: "error - 401 unauthorized",
+			vertexHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantErr:     true,
+			errContains: "aiplatform.user role",
+		},
+		{
+			name: "error - 403 forbidden",
+			vertexHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantErr:     true,
+			errContains: "aiplatform.user role",
+		},
+		{
+			name: "error - 404 region/project mismatch",
+			vertexHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantErr:     true,
+			errContains: "region/project mismatch",
+		},
+		{
+			name: "error - unexpected 500",
+			vertexHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("internal error"))
+			},
+			wantErr:     true,
+			errContains: "unexpected response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vertexServer := httptest.NewServer(tt.vertexHandler)
+			defer vertexServer.Close()
+
+			origEndpoint := vertexAIEndpoint
+			vertexAIEndpoint = func(region, projectID string) string { return vertexServer.URL }
+			defer func() { vertexAIEndpoint = origEndpoint }()
+
+			origClient := liveProbeHTTPClient
+			liveProbeHTTPClient = vertexServer.Client()
+			defer func() { liveProbeHTTPClient = origClient }()
+
+			err := runLiveAuthProbe(context.Background(), fakeServiceAccountKeyJSON(tokenServer.URL), "test-project", "us-central1")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("runLiveAuthProbe() expected error but got nil")
+				}
+				if tt.errContains != "" && !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("runLiveAuthProbe() error = %v, want error containing %q", err, tt.errContains)
+				}
+			} else if err != nil {
+				t.Fatalf("runLiveAuthProbe() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRunLiveAuthProbe_NetworkError(t *testing.T) {
+	tokenServer := newTokenServer(t)
+	defer tokenServer.Close()
+
+	origEndpoint := vertexAIEndpoint
+	vertexAIEndpoint = func(region, projectID string) string { return "http://127.0.0.1:0" }
+	defer func() { vertexAIEndpoint = origEndpoint }()
+
+	err := runLiveAuthProbe(context.Background(), fakeServiceAccountKeyJSON(tokenServer.URL), "test-project", "us-central1")
+	if err == nil {
+		t.Fatal("runLiveAuthProbe() expected a network error but got nil")
+	}
+	if !strings.Contains(err.Error(), "network error") {
+		t.Fatalf("runLiveAuthProbe() error = %v, want a network error", err)
+	}
+}
+
+func TestLiveAuthProbeEnabled(t *testing.T) {
+	orig := os.Getenv(liveCheckEnvVar)
+	defer os.Setenv(liveCheckEnvVar, orig)
+
+	os.Unsetenv(liveCheckEnvVar)
+	if !liveAuthProbeEnabled() {
+		t.Error("liveAuthProbeEnabled() should default to true when unset")
+	}
+
+	os.Setenv(liveCheckEnvVar, "0")
+	if liveAuthProbeEnabled() {
+		t.Error("liveAuthProbeEnabled() should be false when set to 0")
+	}
+
+	os.Setenv(liveCheckEnvVar, "false")
+	if liveAuthProbeEnabled() {
+		t.Error("liveAuthProbeEnabled() should be false when set to false")
+	}
+
+	os.Setenv(liveCheckEnvVar, "1")
+	if !liveAuthProbeEnabled() {
+		t.Error("liveAuthProbeEnabled() should be true when set to 1")
+	}
+}
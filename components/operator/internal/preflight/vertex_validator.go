@@ -0,0 +1,32 @@
+package preflight
+
+import (
+	"context"
+	"os"
+)
+
+// VertexValidator adapts ValidateVertexConfigWithResult to the Validator
+// interface so it can run alongside the other LLM backend validators.
+type VertexValidator struct {
+	OperatorNamespace string
+}
+
+// NewVertexValidator returns a Validator for Vertex AI configuration.
+func NewVertexValidator(operatorNamespace string) *VertexValidator {
+	return &VertexValidator{OperatorNamespace: operatorNamespace}
+}
+
+// Name implements Validator.
+func (v *VertexValidator) Name() string { return "vertex" }
+
+// Enabled implements Validator. Vertex AI is selected with
+// CLAUDE_CODE_USE_VERTEX=1.
+func (v *VertexValidator) Enabled() bool {
+	return os.Getenv("CLAUDE_CODE_USE_VERTEX") == "1"
+}
+
+// Validate implements Validator.
+func (v *VertexValidator) Validate(ctx context.Context) error {
+	_, err := ValidateVertexConfigWithResult(v.OperatorNamespace)
+	return err
+}
@@ -0,0 +1,89 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// externalAccountType is the `type` value Google uses for Workload Identity
+// Federation credential files, as opposed to "service_account" for a
+// long-lived key.
+const externalAccountType = "external_account"
+
+// audiencePattern matches the //iam.googleapis.com/... resource name Google
+// issues as the audience of a workload identity pool provider.
+var audiencePattern = regexp.MustCompile(`^//iam\.googleapis\.com/`)
+
+// VertexSecretValidationResult carries the outcome of validating the
+// ambient-vertex secret, including which authentication mode it uses.
+type VertexSecretValidationResult struct {
+	AuthMode types.AmbientVertexAuthMode
+}
+
+// detectVertexAuthMode inspects the `type` field of key.json to decide
+// whether the secret holds a static service-account key or a Workload
+// Identity Federation external_account credential file.
+func detectVertexAuthMode(data map[string]any) types.AmbientVertexAuthMode {
+	if typeVal, ok := data["type"].(string); ok && typeVal == externalAccountType {
+		return types.AmbientVertexAuthModeWorkloadIdentityFederation
+	}
+	return types.AmbientVertexAuthModeServiceAccountKey
+}
+
+// validateWorkloadIdentitySecret validates the structure of a Workload
+// Identity Federation external_account credential file. Unlike a static
+// service-account key, there is no private key material to verify - instead
+// we confirm token_url and audience are well-formed and that the projected
+// token file the credential_source points at is actually readable from
+// within the operator pod.
+func validateWorkloadIdentitySecret(secret *corev1.Secret) error {
+	if secret == nil {
+		return fmt.Errorf("secret is nil")
+	}
+
+	if secret.Data["key.json"] == nil {
+		return fmt.Errorf("secret missing 'key.json' key - ensure secret was created with --from-file=key.json=/path/to/file")
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(secret.Data["key.json"], &data); err != nil {
+		return fmt.Errorf("'key.json' is not valid JSON: %w", err)
+	}
+
+	requiredFields := []string{"type", "audience", "subject_token_type", "token_url", "credential_source"}
+	for _, field := range requiredFields {
+		if _, ok := data[field]; !ok {
+			return fmt.Errorf("'key.json' missing required field '%s' - doesn't appear to be a valid external_account credential file", field)
+		}
+	}
+
+	audience, ok := data["audience"].(string)
+	if !ok || !audiencePattern.MatchString(audience) {
+		return fmt.Errorf("'audience' (%v) is not a valid workload identity pool provider resource name (expected '//iam.googleapis.com/...')", data["audience"])
+	}
+
+	if err := validateHTTPSURLField("token_url", data["token_url"]); err != nil {
+		return err
+	}
+
+	credentialSource, ok := data["credential_source"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("'credential_source' must be an object")
+	}
+
+	filePath, ok := credentialSource["file"].(string)
+	if !ok || filePath == "" {
+		return fmt.Errorf("'credential_source.file' is missing or not a string - only file-based credential sources are supported")
+	}
+
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("'credential_source.file' (%s) is not readable from the operator pod: %w", filePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package preflight
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// validWIFCredentialJSON returns a well-formed external_account credential
+// file referencing credentialSourcePath as its token file.
+func validWIFCredentialJSON(credentialSourcePath string) []byte {
+	data := map[string]any{
+		"type":               "external_account",
+		"audience":           "//iam.googleapis.com/projects/123456789/locations/global/workloadIdentityPools/my-pool/providers/my-provider",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          "https://sts.googleapis.com/v1/token",
+		"credential_source": map[string]any{
+			"file": credentialSourcePath,
+		},
+	}
+	raw, _ := json.Marshal(data)
+	return raw
+}
+
+// TestDetectVertexAuthMode covers the `type` field dispatch between a static
+// service-account key and a Workload Identity Federation credential file.
+func TestDetectVertexAuthMode(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]any
+		want types.AmbientVertexAuthMode
+	}{
+		{
+			name: "service_account",
+			data: map[string]any{"type": "service_account"},
+			want: types.AmbientVertexAuthModeServiceAccountKey,
+		},
+		{
+			name: "external_account",
+			data: map[string]any{"type": "external_account"},
+			want: types.AmbientVertexAuthModeWorkloadIdentityFederation,
+		},
+		{
+			name: "missing type",
+			data: map[string]any{},
+			want: types.AmbientVertexAuthModeServiceAccountKey,
+		},
+		{
+			name: "type not a string",
+			data: map[string]any{"type": 123},
+			want: types.AmbientVertexAuthModeServiceAccountKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectVertexAuthMode(tt.data); got != tt.want {
+				t.Errorf("detectVertexAuthMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateWorkloadIdentitySecret covers structural validation of an
+// external_account credential file, including the credential_source.file
+// readability check.
+func TestValidateWorkloadIdentitySecret(t *testing.T) {
+	tokenFile, err := os.CreateTemp("", "wif-token-*")
+	if err != nil {
+		t.Fatalf("failed to create temp token file: %v", err)
+	}
+	defer os.Remove(tokenFile.Name())
+	tokenFile.Close()
+
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid WIF secret",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{"key.json": validWIFCredentialJSON(tokenFile.Name())},
+			},
+		},
+		{
+			name:        "nil secret",
+			secret:      nil,
+			wantErr:     true,
+			errContains: "secret is nil",
+		},
+		{
+			name:        "missing key.json",
+			secret:      &corev1.Secret{Data: map[string][]byte{}},
+			wantErr:     true,
+			errContains: "missing 'key.json' key",
+		},
+		{
+			name:        "invalid JSON",
+			secret:      &corev1.Secret{Data: map[string][]byte{"key.json": []byte("not json")}},
+			wantErr:     true,
+			errContains: "not valid JSON",
+		},
+		{
+			name: "missing required field",
+			secret: &corev1.Secret{Data: map[string][]byte{"key.json": []byte(`{
+				"type": "external_account",
+				"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/p/providers/p",
+				"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+				"token_url": "https://sts.googleapis.com/v1/token"
+			}`)}},
+			wantErr:     true,
+			errContains: "missing required field 'credential_source'",
+		},
+		{
+			name: "malformed audience",
+			secret: &corev1.Secret{Data: map[string][]byte{"key.json": []byte(`{
+				"type": "external_account",
+				"audience": "not-a-workload-identity-resource",
+				"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+				"token_url": "https://sts.googleapis.com/v1/token",
+				"credential_source": {"file": "` + tokenFile.Name() + `"}
+			}`)}},
+			wantErr:     true,
+			errContains: "not a valid workload identity pool provider resource name",
+		},
+		{
+			name: "non-https token_url",
+			secret: &corev1.Secret{Data: map[string][]byte{"key.json": []byte(`{
+				"type": "external_account",
+				"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/p/providers/p",
+				"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+				"token_url": "http://sts.googleapis.com/v1/token",
+				"credential_source": {"file": "` + tokenFile.Name() + `"}
+			}`)}},
+			wantErr:     true,
+			errContains: "must use https",
+		},
+		{
+			name: "credential_source not an object",
+			secret: &corev1.Secret{Data: map[string][]byte{"key.json": []byte(`{
+				"type": "external_account",
+				"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/p/providers/p",
+				"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+				"token_url": "https://sts.googleapis.com/v1/token",
+				"credential_source": "not-an-object"
+			}`)}},
+			wantErr:     true,
+			errContains: "'credential_source' must be an object",
+		},
+		{
+			name: "credential_source.file missing",
+			secret: &corev1.Secret{Data: map[string][]byte{"key.json": []byte(`{
+				"type": "external_account",
+				"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/p/providers/p",
+				"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+				"token_url": "https://sts.googleapis.com/v1/token",
+				"credential_source": {}
+			}`)}},
+			wantErr:     true,
+			errContains: "'credential_source.file' is missing",
+		},
+		{
+			name:        "credential_source.file unreadable",
+			secret:      &corev1.Secret{Data: map[string][]byte{"key.json": validWIFCredentialJSON("/nonexistent/path/token")}},
+			wantErr:     true,
+			errContains: "is not readable from the operator pod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkloadIdentitySecret(tt.secret)
+			assertErr(t, err, tt.wantErr, tt.errContains)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// defaultWatcherDebounce is how long Watcher waits after the most recent
+// Add/Update/Delete event before re-validating, so a burst of writes during a
+// rotation (e.g. a controller deleting and recreating the secret) triggers
+// one recheck instead of one per event.
+const defaultWatcherDebounce = 2 * time.Second
+
+// vertexSecretValidGauge is toggled by Watcher on every re-validation of the
+// ambient-vertex secret. It is a GaugeVec rather than a plain Gauge so the
+// failure reason can ride along as a label for alerting; Reset before each
+// Set so only the current reason (or none, when valid) is ever exposed.
+var vertexSecretValidGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "vteam_preflight_vertex_valid",
+	Help: "1 if the ambient-vertex secret last validated successfully, 0 otherwise.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(vertexSecretValidGauge)
+}
+
+// Result is a snapshot of the most recent ambient-vertex secret validation.
+type Result struct {
+	Valid     bool
+	Reason    string
+	CheckedAt time.Time
+}
+
+// Watcher keeps validating the ambient-vertex secret for as long as the
+// operator runs, instead of only once at startup, so a rotated or deleted
+// secret is caught within seconds rather than at the next restart.
+type Watcher struct {
+	// OperatorNamespace is the namespace the ambient-vertex secret and the
+	// operator's own Deployment live in.
+	OperatorNamespace string
+	// DeploymentName is the operator's own Deployment, used as the
+	// InvolvedObject for the Kubernetes Events this Watcher emits.
+	DeploymentName string
+	// Debounce overrides defaultWatcherDebounce; zero keeps the default.
+	// Exposed mainly so tests can shrink it.
+	Debounce time.Duration
+
+	kubeClient kubernetes.Interface
+	recorder   record.EventRecorder
+
+	result atomic.Pointer[Result]
+
+	mu    sync.Mutex
+	timer *time.Timer
+
+	rechecked chan struct{}
+}
+
+// NewWatcher returns a Watcher for the ambient-vertex secret in
+// operatorNamespace. The returned Watcher reports LastResult().Valid == true until the
+// first recheck completes, since an operator only starts this Watcher after
+// its own one-shot startup preflight has already passed.
+func NewWatcher(kubeClient kubernetes.Interface, operatorNamespace, deploymentName string) *Watcher {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: kubeClient.CoreV1().Events(operatorNamespace),
+	})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "vteam-operator-preflight"})
+
+	w := &Watcher{
+		OperatorNamespace: operatorNamespace,
+		DeploymentName:    deploymentName,
+		kubeClient:        kubeClient,
+		recorder:          recorder,
+		rechecked:         make(chan struct{}, 1),
+	}
+	w.result.Store(&Result{Valid: true, CheckedAt: time.Time{}})
+	return w
+}
+
+// Start runs a SharedInformerFactory scoped to OperatorNamespace, watching
+// for Add/Update/Delete events on the ambient-vertex secret, until ctx is
+// cancelled. It blocks until the informer's initial cache sync completes.
+func (w *Watcher) Start(ctx context.Context) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		w.kubeClient, 0, informers.WithNamespace(w.OperatorNamespace),
+	)
+	informer := factory.Core().V1().Secrets().Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { w.onSecretEvent(obj) },
+		UpdateFunc: func(oldObj, newObj any) { w.onSecretEvent(newObj) },
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			w.onSecretEvent(obj)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register ambient-vertex secret event handler: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("ambient-vertex secret informer cache never synced")
+	}
+	return nil
+}
+
+// onSecretEvent debounces rapid Add/Update/Delete events into a single recheck.
+func (w *Watcher) onSecretEvent(obj any) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.Name != types.AmbientVertexSecretName {
+		return
+	}
+
+	debounce := w.Debounce
+	if debounce == 0 {
+		debounce = defaultWatcherDebounce
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(debounce, w.recheck)
+}
+
+// recheck re-runs full Vertex AI validation and publishes the outcome to the
+// Prometheus gauge, a Kubernetes Event on the operator Deployment, and
+// LastResult.
+func (w *Watcher) recheck() {
+	_, err := ValidateVertexConfigWithResult(w.OperatorNamespace)
+
+	result := &Result{CheckedAt: time.Now()}
+	vertexSecretValidGauge.Reset()
+	if err != nil {
+		result.Valid = false
+		result.Reason = err.Error()
+		vertexSecretValidGauge.WithLabelValues(result.Reason).Set(0)
+		log.Printf("ambient-vertex secret re-validation failed: %v", err)
+		w.emitEvent(corev1.EventTypeWarning, "VertexSecretInvalid", result.Reason)
+	} else {
+		result.Valid = true
+		vertexSecretValidGauge.WithLabelValues("").Set(1)
+		log.Printf("ambient-vertex secret re-validated successfully")
+		w.emitEvent(corev1.EventTypeNormal, "VertexSecretValid", "ambient-vertex secret re-validated successfully")
+	}
+	w.result.Store(result)
+
+	select {
+	case w.rechecked <- struct{}{}:
+	default:
+	}
+}
+
+// emitEvent records a Kubernetes Event against the operator's own
+// Deployment. The EventRecorder aggregates repeated identical events rather
+// than creating a new object each time, so calling this on every recheck is
+// safe even when the outcome is unchanged.
+func (w *Watcher) emitEvent(eventType, reason, message string) {
+	deployment := &corev1.ObjectReference{
+		Kind:      "Deployment",
+		Namespace: w.OperatorNamespace,
+		Name:      w.DeploymentName,
+	}
+	w.recorder.Event(deployment, eventType, reason, message)
+}
+
+// LastResult returns the outcome of the most recent recheck.
+func (w *Watcher) LastResult() Result {
+	return *w.result.Load()
+}
+
+// Rechecked returns a channel that receives a value after every completed
+// recheck, primarily so tests can wait for a debounced recheck to land
+// instead of sleeping.
+func (w *Watcher) Rechecked() <-chan struct{} {
+	return w.rechecked
+}
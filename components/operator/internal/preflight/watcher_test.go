@@ -0,0 +1,135 @@
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ambient-code/vteam/components/operator/internal/config"
+	"github.com/ambient-code/vteam/components/operator/internal/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func waitForRecheck(t *testing.T, w *Watcher) {
+	t.Helper()
+	select {
+	case <-w.Rechecked():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Watcher to recheck the secret")
+	}
+}
+
+func validVertexSecret(namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.AmbientVertexSecretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"key.json": []byte(`{
+				"type": "service_account",
+				"project_id": "test-project-123",
+				"private_key": "` + testServiceAccountPrivateKeyJSON + `",
+				"private_key_id": "0123456789abcdef0123456789abcdef01234567",
+				"client_email": "test@test-project-123.iam.gserviceaccount.com",
+				"auth_uri": "https://accounts.google.com/o/oauth2/auth",
+				"token_uri": "https://oauth2.googleapis.com/token",
+				"auth_provider_x509_cert_url": "https://www.googleapis.com/oauth2/v1/certs",
+				"client_x509_cert_url": "https://www.googleapis.com/robot/v1/metadata/x509/test%40test-project-123.iam.gserviceaccount.com"
+			}`),
+		},
+	}
+}
+
+// TestWatcher_RevalidatesOnSecretChange drives the informer by hand - Create
+// then Update the ambient-vertex secret through the fake clientset - and
+// checks that Watcher re-validates after each change and flips
+// LastResult/ReadyzHandler accordingly.
+func TestWatcher_RevalidatesOnSecretChange(t *testing.T) {
+	envVars := map[string]string{
+		"ANTHROPIC_VERTEX_PROJECT_ID":    "test-project-123",
+		"CLOUD_ML_REGION":                "us-central1",
+		"GOOGLE_APPLICATION_CREDENTIALS": "/path/to/creds.json",
+		"VTEAM_PREFLIGHT_LIVE_CHECK":     "0",
+	}
+	for key, val := range envVars {
+		os.Setenv(key, val)
+	}
+	defer func() {
+		for key := range envVars {
+			os.Unsetenv(key)
+		}
+	}()
+
+	fakeClient := fake.NewSimpleClientset()
+	origK8sClient := config.K8sClient
+	config.K8sClient = fakeClient
+	defer func() { config.K8sClient = origK8sClient }()
+
+	w := NewWatcher(fakeClient, "test-namespace", "vteam-operator")
+	w.Debounce = 10 * time.Millisecond
+
+	if !w.LastResult().Valid {
+		t.Fatal("Watcher should report Valid before its first recheck")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := w.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if _, err := fakeClient.CoreV1().Secrets("test-namespace").Create(
+		ctx, validVertexSecret("test-namespace"), metav1.CreateOptions{},
+	); err != nil {
+		t.Fatalf("failed to create fake secret: %v", err)
+	}
+	waitForRecheck(t, w)
+
+	result := w.LastResult()
+	if !result.Valid {
+		t.Fatalf("LastResult() = %+v, want Valid after a well-formed secret", result)
+	}
+
+	rr := httptest.NewRecorder()
+	w.ReadyzHandler()(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("ReadyzHandler() status = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	invalid := validVertexSecret("test-namespace")
+	invalid.Data["key.json"] = []byte(`not valid json`)
+	if _, err := fakeClient.CoreV1().Secrets("test-namespace").Update(
+		ctx, invalid, metav1.UpdateOptions{},
+	); err != nil {
+		t.Fatalf("failed to update fake secret: %v", err)
+	}
+	waitForRecheck(t, w)
+
+	result = w.LastResult()
+	if result.Valid {
+		t.Fatalf("LastResult() = %+v, want invalid after corrupting the secret", result)
+	}
+	if !contains(result.Reason, "not valid JSON") {
+		t.Errorf("LastResult().Reason = %q, want it to mention the JSON error", result.Reason)
+	}
+
+	rr = httptest.NewRecorder()
+	w.ReadyzHandler()(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("ReadyzHandler() status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHealthzHandler_AlwaysOK(t *testing.T) {
+	rr := httptest.NewRecorder()
+	HealthzHandler()(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("HealthzHandler() status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
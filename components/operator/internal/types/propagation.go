@@ -0,0 +1,57 @@
+package types
+
+// PropagatedResourceKind is the kind of object a PropagationPolicy mirrors
+// into each session namespace.
+type PropagatedResourceKind string
+
+const (
+	// PropagatedResourceSecret copies a Secret.
+	PropagatedResourceSecret PropagatedResourceKind = "Secret"
+	// PropagatedResourceConfigMap copies a ConfigMap.
+	PropagatedResourceConfigMap PropagatedResourceKind = "ConfigMap"
+)
+
+// CopiedFromAnnotation records where a propagated resource came from, as
+// "<namespace>/<name>", so cleanup only ever removes copies the operator
+// created and never a same-named resource an administrator created by hand.
+const CopiedFromAnnotation = "vteam.ambient-code/copied-from"
+
+// PropagationPolicy describes one resource the operator mirrors from its own
+// namespace into every AgenticSession's namespace. This is the declarative
+// replacement for the previously hardcoded single-secret copy: the operator
+// now walks a list of these, one per CA bundle, registry credential, or
+// other resource sessions need mounted, instead of growing a bespoke copy
+// function for each one.
+//
+// Not yet wired: see handlers.PropagateAll's doc comment for the gap
+// between this subsystem and the AgenticSession reconciler that would
+// actually consume it.
+type PropagationPolicy struct {
+	// Kind selects the source API: Secret or ConfigMap.
+	Kind PropagatedResourceKind
+	// SourceNamespace is where the operator reads the resource from -
+	// normally its own namespace.
+	SourceNamespace string
+	// SourceName is the name of the resource to copy. The copy keeps the
+	// same name in the target namespace.
+	SourceName string
+	// Keys restricts the copy to these data keys. Nil or empty copies every
+	// key.
+	Keys []string
+	// Controller sets whether the owner reference added to the copy is a
+	// controlling reference (the copy is garbage-collected with the owner,
+	// the normal case) or a non-controlling one, for resources that are
+	// also owned elsewhere.
+	Controller bool
+}
+
+// DefaultVertexPropagationPolicy is the PropagationPolicy equivalent of the
+// single hardcoded ambient-vertex secret copy this subsystem replaces.
+func DefaultVertexPropagationPolicy(operatorNamespace string) PropagationPolicy {
+	return PropagationPolicy{
+		Kind:            PropagatedResourceSecret,
+		SourceNamespace: operatorNamespace,
+		SourceName:      AmbientVertexSecretName,
+		Controller:      true,
+	}
+}
@@ -0,0 +1,36 @@
+package types
+
+// AmbientVertexSecretName is the name of the secret the operator expects to
+// find in its own namespace holding the Vertex AI credential (either a
+// service-account key or a Workload Identity Federation credential file).
+const AmbientVertexSecretName = "ambient-vertex"
+
+// AmbientAnthropicSecretName is the name of the secret the operator expects
+// to find in its own namespace holding the Anthropic direct API key.
+const AmbientAnthropicSecretName = "ambient-anthropic"
+
+// AmbientAzureSecretName is the name of the secret the operator expects to
+// find in its own namespace holding the Azure OpenAI API key.
+const AmbientAzureSecretName = "ambient-azure-openai"
+
+// OperatorServiceAccountName is the name of the operator's own Kubernetes
+// ServiceAccount, used to look up IRSA role-arn annotations when validating
+// AWS Bedrock configuration.
+const OperatorServiceAccountName = "ambient-code-operator"
+
+// AmbientVertexAuthMode identifies how the operator authenticates to
+// Vertex AI, so callers downstream of preflight validation (e.g. the
+// agentic session pod spec) can decide whether to mount the ambient-vertex
+// secret directly or project a Workload Identity Federation token instead.
+type AmbientVertexAuthMode string
+
+const (
+	// AmbientVertexAuthModeServiceAccountKey is the default: a long-lived
+	// Google service-account JSON key mounted from the ambient-vertex secret.
+	AmbientVertexAuthModeServiceAccountKey AmbientVertexAuthMode = "ServiceAccountKey"
+
+	// AmbientVertexAuthModeWorkloadIdentityFederation authenticates using a
+	// Workload Identity Federation external_account credential file backed by
+	// a cluster-projected OIDC token, so no long-lived key needs to be stored.
+	AmbientVertexAuthModeWorkloadIdentityFederation AmbientVertexAuthMode = "WorkloadIdentityFederation"
+)